@@ -0,0 +1,46 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/owasp-amass/oam-tools/viz"
+)
+
+// FprintCandidates writes each candidate with its score, followed by the reasons supporting it.
+func FprintCandidates(out io.Writer, candidates []*viz.ExpansionCandidate) {
+	if len(candidates) == 0 {
+		fmt.Fprintln(out, blue("No scope expansion candidates were found"))
+		return
+	}
+
+	for i, c := range candidates {
+		fmt.Fprintf(out, "%s %s %s\n", blue(strconv.Itoa(i+1)+"."), green(c.Domain),
+			blue("(score "+strconv.Itoa(c.Score)+")"))
+
+		for _, e := range c.Evidence {
+			fmt.Fprintf(out, "\t%s %s\n", blue(fmt.Sprintf("%-11s", e.Type)), yellow(evidenceReason(e)))
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// evidenceReason describes the asset shared by the candidate and the scope.
+func evidenceReason(e *viz.ExpansionEvidence) string {
+	switch e.Type {
+	case viz.EvidenceRegistrant:
+		return fmt.Sprintf("%s shares the registrant %q with %s", e.Name, e.Value, e.InScope)
+	case viz.EvidenceNameServer:
+		return fmt.Sprintf("%s uses the name server %s, like %s", e.Name, e.Value, e.InScope)
+	case viz.EvidenceCertificate:
+		return fmt.Sprintf("%s is on certificate %s, along with %s", e.Name, e.Value, e.InScope)
+	case viz.EvidenceAddress:
+		return fmt.Sprintf("%s resolves to %s, like %s", e.Name, e.Value, e.InScope)
+	}
+	return fmt.Sprintf("%s shares %s with %s", e.Name, e.Value, e.InScope)
+}
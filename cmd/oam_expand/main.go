@@ -0,0 +1,228 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+// oam_expand: Propose root domains to add to the scope, ranked by the evidence they share with it
+//
+//	+----------------------------------------------------------------------------+
+//	| ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░  OWASP Amass  ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░ |
+//	+----------------------------------------------------------------------------+
+//	|      .+++:.            :                             .+++.                 |
+//	|    +W@@@@@@8        &+W@#               o8W8:      +W@@@@@@#.   oW@@@W#+   |
+//	|   &@#+   .o@##.    .@@@o@W.o@@o       :@@#&W8o    .@#:  .:oW+  .@#+++&#&   |
+//	|  +@&        &@&     #@8 +@W@&8@+     :@W.   +@8   +@:          .@8         |
+//	|  8@          @@     8@o  8@8  WW    .@W      W@+  .@W.          o@#:       |
+//	|  WW          &@o    &@:  o@+  o@+   #@.      8@o   +W@#+.        +W@8:     |
+//	|  #@          :@W    &@+  &@+   @8  :@o       o@o     oW@@W+        oW@8    |
+//	|  o@+          @@&   &@+  &@+   #@  &@.      .W@W       .+#@&         o@W.  |
+//	|   WW         +@W@8. &@+  :&    o@+ #@      :@W&@&         &@:  ..     :@o  |
+//	|   :@W:      o@# +Wo &@+        :W: +@W&o++o@W. &@&  8@#o+&@W.  #@:    o@+  |
+//	|    :W@@WWWW@@8       +              :&W@@@@&    &W  .o#@@W&.   :W@WWW@@&   |
+//	|      +o&&&&+.                                                    +oooo.    |
+//	+----------------------------------------------------------------------------+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/caffix/stringset"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/config/config"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/viz"
+)
+
+const (
+	timeFormat = "01/02 15:04:05 2006 MST"
+	usageMsg   = "[options] -d domain"
+)
+
+var (
+	// Colors used to ease the reading of program output
+	g      = color.New(color.FgHiGreen)
+	r      = color.New(color.FgHiRed)
+	yellow = color.New(color.FgHiYellow).SprintFunc()
+	green  = color.New(color.FgHiGreen).SprintFunc()
+	blue   = color.New(color.FgHiBlue).SprintFunc()
+)
+
+type expandArgs struct {
+	Domains  *stringset.Set
+	MinScore int
+	Limit    int
+	Since    string
+	Options  struct {
+		NoColor bool
+		Silent  bool
+	}
+	Filepaths struct {
+		ConfigFile string
+		Directory  string
+		Domains    string
+		JSONOutput string
+	}
+}
+
+func main() {
+	var args expandArgs
+	var help1, help2 bool
+	expCommand := flag.NewFlagSet("expand", flag.ContinueOnError)
+
+	args.Domains = stringset.New()
+	defer args.Domains.Close()
+
+	expBuf := new(bytes.Buffer)
+	expCommand.SetOutput(expBuf)
+
+	expCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	expCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	expCommand.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
+	expCommand.IntVar(&args.MinScore, "min", 2, "Minimum evidence score for a candidate to be listed")
+	expCommand.IntVar(&args.Limit, "max", 0, "Maximum number of candidates to list (0 lists all of them)")
+	expCommand.StringVar(&args.Since, "since", "", "Include only assets validated after (format: "+timeFormat+")")
+	expCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	expCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	expCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file")
+	expCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
+	expCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing root domain names")
+	expCommand.StringVar(&args.Filepaths.JSONOutput, "json", "", "Path to the JSON output file")
+
+	var usage = func() {
+		g.Fprintf(color.Error, "Usage: %s %s\n\n", path.Base(os.Args[0]), usageMsg)
+		expCommand.PrintDefaults()
+		g.Fprintln(color.Error, expBuf.String())
+	}
+
+	if len(os.Args) < 2 {
+		usage()
+		return
+	}
+	if err := expCommand.Parse(os.Args[1:]); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 {
+		usage()
+		return
+	}
+	if args.Options.NoColor {
+		color.NoColor = true
+	}
+	if args.Options.Silent {
+		color.Output = io.Discard
+		color.Error = io.Discard
+	}
+	if args.Filepaths.Domains != "" {
+		list, err := config.GetListFromFile(args.Filepaths.Domains)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to parse the domain names file: %v\n", err)
+			os.Exit(1)
+		}
+		args.Domains.InsertMany(list...)
+	}
+
+	var start time.Time
+	if args.Since != "" {
+		var err error
+
+		start, err = time.Parse(timeFormat, args.Since)
+		if err != nil {
+			r.Fprintf(color.Error, "%s is not in the correct format: %s\n", args.Since, timeFormat)
+			os.Exit(1)
+		}
+		start = start.UTC()
+	}
+
+	cfg := config.NewConfig()
+	// Check if a configuration file was provided, and if so, load the settings
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err == nil {
+		if args.Filepaths.Directory == "" {
+			args.Filepaths.Directory = cfg.Dir
+		}
+		if args.Domains.Len() == 0 {
+			args.Domains.InsertMany(cfg.Domains()...)
+		}
+	} else if args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	if args.Domains.Len() == 0 {
+		r.Fprintln(color.Error, "No root domain names were provided")
+		os.Exit(1)
+	}
+	// Connect with the graph database containing the enumeration data
+	db := openGraphDatabase(args.Filepaths.Directory, cfg)
+	if db == nil {
+		r.Fprintln(color.Error, "Failed to connect with the database")
+		os.Exit(1)
+	}
+
+	candidates := selectCandidates(viz.ExpansionCandidates(args.Domains.Slice(), start, db), args.MinScore, args.Limit)
+
+	FprintCandidates(color.Output, candidates)
+	if args.Filepaths.JSONOutput != "" {
+		if err := writeJSONFile(args.Filepaths.JSONOutput, candidates); err != nil {
+			r.Fprintf(color.Error, "Failed to write the JSON output file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// selectCandidates keeps the ranked candidates meeting the minimum score, up to the limit when it is positive.
+func selectCandidates(candidates []*viz.ExpansionCandidate, min, limit int) []*viz.ExpansionCandidate {
+	results := []*viz.ExpansionCandidate{}
+
+	for _, c := range candidates {
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+		if c.Score >= min {
+			results = append(results, c)
+		}
+	}
+	return results
+}
+
+func writeJSONFile(path string, v any) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func openGraphDatabase(dir string, cfg *config.Config) *graph.Graph {
+	// Add the local database settings to the configuration
+	cfg.GraphDBs = append(cfg.GraphDBs, cfg.LocalDatabaseSettings(cfg.GraphDBs))
+
+	for _, db := range cfg.GraphDBs {
+		if db.Primary {
+			var g *graph.Graph
+
+			if db.System == "local" {
+				g = graph.NewGraph(db.System, filepath.Join(config.OutputDirectory(cfg.Dir), "amass.sqlite"), db.Options)
+			} else {
+				connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s", db.Host, db.Port, db.Username, db.Password, db.DBName)
+				g = graph.NewGraph(db.System, connStr, db.Options)
+			}
+
+			if g != nil {
+				return g
+			}
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+// oam_findings: Evaluate YAML rules against the assets within the scope and report the findings by severity
+//
+//	+----------------------------------------------------------------------------+
+//	| ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░  OWASP Amass  ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░ |
+//	+----------------------------------------------------------------------------+
+//	|      .+++:.            :                             .+++.                 |
+//	|    +W@@@@@@8        &+W@#               o8W8:      +W@@@@@@#.   oW@@@W#+   |
+//	|   &@#+   .o@##.    .@@@o@W.o@@o       :@@#&W8o    .@#:  .:oW+  .@#+++&#&   |
+//	|  +@&        &@&     #@8 +@W@&8@+     :@W.   +@8   +@:          .@8         |
+//	|  8@          @@     8@o  8@8  WW    .@W      W@+  .@W.          o@#:       |
+//	|  WW          &@o    &@:  o@+  o@+   #@.      8@o   +W@#+.        +W@8:     |
+//	|  #@          :@W    &@+  &@+   @8  :@o       o@o     oW@@W+        oW@8    |
+//	|  o@+          @@&   &@+  &@+   #@  &@.      .W@W       .+#@&         o@W.  |
+//	|   WW         +@W@8. &@+  :&    o@+ #@      :@W&@&         &@:  ..     :@o  |
+//	|   :@W:      o@# +Wo &@+        :W: +@W&o++o@W. &@&  8@#o+&@W.  #@:    o@+  |
+//	|    :W@@WWWW@@8       +              :&W@@@@&    &W  .o#@@W&.   :W@WWW@@&   |
+//	|      +o&&&&+.                                                    +oooo.    |
+//	+----------------------------------------------------------------------------+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caffix/stringset"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/config/config"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/findings"
+)
+
+const (
+	timeFormat = "01/02 15:04:05 2006 MST"
+	usageMsg   = "[options] -d domain"
+)
+
+var (
+	// Colors used to ease the reading of program output
+	g      = color.New(color.FgHiGreen)
+	r      = color.New(color.FgHiRed)
+	yellow = color.New(color.FgHiYellow).SprintFunc()
+	green  = color.New(color.FgHiGreen).SprintFunc()
+	blue   = color.New(color.FgHiBlue).SprintFunc()
+)
+
+type findingsArgs struct {
+	Domains     *stringset.Set
+	Rules       string
+	ASNs        string
+	MinSeverity string
+	Since       string
+	Options     struct {
+		List       bool
+		NoColor    bool
+		NoDefaults bool
+		Silent     bool
+	}
+	Filepaths struct {
+		ConfigFile  string
+		Directory   string
+		Domains     string
+		JSONOutput  string
+		SARIFOutput string
+	}
+}
+
+func main() {
+	var args findingsArgs
+	var help1, help2 bool
+	fCommand := flag.NewFlagSet("findings", flag.ContinueOnError)
+
+	args.Domains = stringset.New()
+	defer args.Domains.Close()
+
+	fBuf := new(bytes.Buffer)
+	fCommand.SetOutput(fBuf)
+
+	fCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	fCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	fCommand.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
+	fCommand.StringVar(&args.Rules, "rules", "", "Paths to YAML rule files separated by commas")
+	fCommand.BoolVar(&args.Options.NoDefaults, "nodefaults", false, "Do not evaluate the default rule pack")
+	fCommand.StringVar(&args.ASNs, "asn", "", "Corporate ASNs separated by commas, for the rules on outside networks")
+	fCommand.StringVar(&args.MinSeverity, "severity", findings.SeverityInfo, "Minimum severity of the findings to report ("+strings.Join(findings.Severities, ", ")+")")
+	fCommand.BoolVar(&args.Options.List, "list", false, "Print the rules that would be evaluated and exit")
+	fCommand.StringVar(&args.Since, "since", "", "Include only assets validated after (format: "+timeFormat+")")
+	fCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	fCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	fCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file")
+	fCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
+	fCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing root domain names")
+	fCommand.StringVar(&args.Filepaths.JSONOutput, "json", "", "Path to the JSON output file")
+	fCommand.StringVar(&args.Filepaths.SARIFOutput, "sarif", "", "Path to the SARIF 2.1.0 output file")
+
+	var usage = func() {
+		g.Fprintf(color.Error, "Usage: %s %s\n\n", path.Base(os.Args[0]), usageMsg)
+		fCommand.PrintDefaults()
+		g.Fprintln(color.Error, fBuf.String())
+	}
+
+	if len(os.Args) < 2 {
+		usage()
+		return
+	}
+	if err := fCommand.Parse(os.Args[1:]); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 {
+		usage()
+		return
+	}
+	if args.Options.NoColor {
+		color.NoColor = true
+	}
+	if args.Options.Silent {
+		color.Output = io.Discard
+		color.Error = io.Discard
+	}
+	if findings.SeverityRank(args.MinSeverity) < 0 {
+		r.Fprintf(color.Error, "%s is not a valid severity, use one of: %s\n", args.MinSeverity, strings.Join(findings.Severities, ", "))
+		os.Exit(1)
+	}
+
+	rs, err := loadRuleSet(&args)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to load the rules: %v\n", err)
+		os.Exit(1)
+	}
+	if args.Options.List {
+		FprintRules(color.Output, rs)
+		return
+	}
+	if len(rs.Rules) == 0 {
+		r.Fprintln(color.Error, "No rules were provided")
+		os.Exit(1)
+	}
+
+	if args.Filepaths.Domains != "" {
+		list, err := config.GetListFromFile(args.Filepaths.Domains)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to parse the domain names file: %v\n", err)
+			os.Exit(1)
+		}
+		args.Domains.InsertMany(list...)
+	}
+
+	var start time.Time
+	if args.Since != "" {
+		var err error
+
+		start, err = time.Parse(timeFormat, args.Since)
+		if err != nil {
+			r.Fprintf(color.Error, "%s is not in the correct format: %s\n", args.Since, timeFormat)
+			os.Exit(1)
+		}
+		start = start.UTC()
+	}
+
+	cfg := config.NewConfig()
+	// Check if a configuration file was provided, and if so, load the settings
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err == nil {
+		if args.Filepaths.Directory == "" {
+			args.Filepaths.Directory = cfg.Dir
+		}
+		if args.Domains.Len() == 0 {
+			args.Domains.InsertMany(cfg.Domains()...)
+		}
+	} else if args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	if args.Domains.Len() == 0 {
+		r.Fprintln(color.Error, "No root domain names were provided")
+		os.Exit(1)
+	}
+	if rs.UsesCorporateASNs() && len(rs.Corporate.ASNs) == 0 {
+		r.Fprintln(color.Error, "No corporate ASNs were provided, so the rules on outside networks will be skipped")
+	}
+	// Connect with the graph database containing the enumeration data
+	db := openGraphDatabase(args.Filepaths.Directory, cfg)
+	if db == nil {
+		r.Fprintln(color.Error, "Failed to connect with the database")
+		os.Exit(1)
+	}
+
+	results := selectFindings(findings.Evaluate(rs, args.Domains.Slice(), start, time.Now(), db), args.MinSeverity)
+
+	FprintFindings(color.Output, results)
+	if args.Filepaths.JSONOutput != "" {
+		if err := writeJSONFile(args.Filepaths.JSONOutput, results); err != nil {
+			r.Fprintf(color.Error, "Failed to write the JSON output file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if args.Filepaths.SARIFOutput != "" {
+		if err := writeSARIFFile(args.Filepaths.SARIFOutput, rs.Rules, results); err != nil {
+			r.Fprintf(color.Error, "Failed to write the SARIF output file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// loadRuleSet merges the default rule pack, the rule files and the corporate ASNs provided on the command line.
+func loadRuleSet(args *findingsArgs) (*findings.RuleSet, error) {
+	rs := new(findings.RuleSet)
+
+	if !args.Options.NoDefaults {
+		defaults, err := findings.DefaultRules()
+		if err != nil {
+			return nil, err
+		}
+		rs = defaults
+	}
+
+	var paths []string
+	for _, p := range strings.Split(args.Rules, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) > 0 {
+		files, err := findings.LoadRules(paths...)
+		if err != nil {
+			return nil, err
+		}
+		if err := rs.Merge(files); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, a := range strings.Split(args.ASNs, ",") {
+		if a = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(a)), "AS"); a == "" {
+			continue
+		}
+
+		asn, err := strconv.Atoi(a)
+		if err != nil || asn <= 0 {
+			return nil, fmt.Errorf("%s is not a valid ASN", a)
+		}
+		rs.Corporate.ASNs = append(rs.Corporate.ASNs, asn)
+	}
+	return rs, nil
+}
+
+// selectFindings keeps the findings at or above the minimum severity.
+func selectFindings(results []*findings.Finding, min string) []*findings.Finding {
+	selected := []*findings.Finding{}
+	rank := findings.SeverityRank(min)
+
+	for _, f := range results {
+		if findings.SeverityRank(f.Severity) >= rank {
+			selected = append(selected, f)
+		}
+	}
+	return selected
+}
+
+func writeJSONFile(path string, v any) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeSARIFFile(path string, rules []*findings.Rule, results []*findings.Finding) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return findings.WriteSARIF(f, "oam_findings", rules, results)
+}
+
+func openGraphDatabase(dir string, cfg *config.Config) *graph.Graph {
+	// Add the local database settings to the configuration
+	cfg.GraphDBs = append(cfg.GraphDBs, cfg.LocalDatabaseSettings(cfg.GraphDBs))
+
+	for _, db := range cfg.GraphDBs {
+		if db.Primary {
+			var g *graph.Graph
+
+			if db.System == "local" {
+				g = graph.NewGraph(db.System, filepath.Join(config.OutputDirectory(cfg.Dir), "amass.sqlite"), db.Options)
+			} else {
+				connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s", db.Host, db.Port, db.Username, db.Password, db.DBName)
+				g = graph.NewGraph(db.System, connStr, db.Options)
+			}
+
+			if g != nil {
+				return g
+			}
+			break
+		}
+	}
+	return nil
+}
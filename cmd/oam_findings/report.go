@@ -0,0 +1,65 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/owasp-amass/oam-tools/findings"
+)
+
+// FprintFindings writes the findings grouped by severity, from the most to the least severe.
+func FprintFindings(out io.Writer, results []*findings.Finding) {
+	if len(results) == 0 {
+		fmt.Fprintln(out, blue("No findings were produced by the rules"))
+		return
+	}
+
+	var severity string
+	for _, f := range results {
+		if f.Severity != severity {
+			if severity != "" {
+				fmt.Fprintln(out)
+			}
+			severity = f.Severity
+			fmt.Fprintf(out, "%s %s\n", blue(strings.ToUpper(severity)), blue("("+strconv.Itoa(countSeverity(results, severity))+")"))
+		}
+
+		fmt.Fprintf(out, "\t%s %s %s\n", green(f.Asset), blue("["+f.RuleID+"]"), f.Rule)
+		for _, e := range f.Evidence {
+			fmt.Fprintf(out, "\t\t%s\n", yellow(e))
+		}
+	}
+}
+
+// FprintRules writes the rules of the set along with the corporate ASNs.
+func FprintRules(out io.Writer, rs *findings.RuleSet) {
+	for _, rule := range rs.Rules {
+		fmt.Fprintf(out, "%s %s %s %s\n", green(fmt.Sprintf("%-34s", rule.ID)),
+			yellow(fmt.Sprintf("%-8s", rule.Severity)), blue(fmt.Sprintf("%-14s", rule.Asset)), rule.Name)
+	}
+
+	var asns []string
+	for _, asn := range rs.Corporate.ASNs {
+		asns = append(asns, "AS"+strconv.Itoa(asn))
+	}
+	if len(asns) > 0 {
+		fmt.Fprintf(out, "\n%s %s\n", blue("Corporate ASNs:"), strings.Join(asns, ", "))
+	}
+}
+
+func countSeverity(results []*findings.Finding, severity string) int {
+	var count int
+
+	for _, f := range results {
+		if f.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,125 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/oam-tools/findings"
+	"github.com/owasp-amass/oam-tools/viz"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+// FprintCertificates writes each certificate with its validity window, SANs, hosts and flags.
+func FprintCertificates(out io.Writer, certs []*viz.CertificateInfo) {
+	if len(certs) == 0 {
+		fmt.Fprintln(out, blue("No certificates were found for the scope"))
+		return
+	}
+
+	for _, c := range certs {
+		fmt.Fprintf(out, "%s %s\n", blue("Serial:"), green(c.SerialNumber))
+		fprintField(out, "Subject", c.Subject)
+		fprintField(out, "Issuer", c.Issuer)
+		fprintField(out, "Valid", formatCertTime(c.NotBefore)+" - "+formatCertTime(c.NotAfter))
+		fprintField(out, "SANs", strings.Join(c.SANs, ", "))
+		fprintField(out, "Hosts", strings.Join(c.Hosts, ", "))
+
+		if flags := certificateFlags(c); len(flags) > 0 {
+			fmt.Fprintf(out, "\t%s %s\n", blue(fmt.Sprintf("%-9s", "Flags")), r.Sprint(strings.Join(flags, "; ")))
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+func certificateFlags(c *viz.CertificateInfo) []string {
+	var flags []string
+
+	if c.Expired {
+		flags = append(flags, "expired")
+	}
+	if c.ExpiringSoon {
+		flags = append(flags, "expiring soon")
+	}
+	if c.SelfSigned {
+		flags = append(flags, "self-signed")
+	}
+	if len(c.OutOfScopeSANs) > 0 {
+		flags = append(flags, "SANs outside the scope: "+strings.Join(c.OutOfScopeSANs, ", "))
+	}
+	return flags
+}
+
+func fprintField(out io.Writer, label, value string) {
+	if value != "" {
+		fmt.Fprintf(out, "\t%s %s\n", blue(fmt.Sprintf("%-9s", label)), yellow(value))
+	}
+}
+
+func formatCertTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// certificateRecords returns the certificates as CSV records, starting with the header.
+func certificateRecords(certs []*viz.CertificateInfo) [][]string {
+	records := [][]string{{
+		"serial_number", "subject", "issuer", "not_before", "not_after", "sans",
+		"hosts", "expired", "expiring_soon", "self_signed", "out_of_scope_sans",
+	}}
+
+	for _, c := range certs {
+		records = append(records, []string{
+			c.SerialNumber,
+			c.Subject,
+			c.Issuer,
+			formatCertTime(c.NotBefore),
+			formatCertTime(c.NotAfter),
+			strings.Join(c.SANs, " "),
+			strings.Join(c.Hosts, " "),
+			strconv.FormatBool(c.Expired),
+			strconv.FormatBool(c.ExpiringSoon),
+			strconv.FormatBool(c.SelfSigned),
+			strings.Join(c.OutOfScopeSANs, " "),
+		})
+	}
+	return records
+}
+
+// certificateFindings reports the expired, expiring and self-signed certificates as results of the
+// default certificate rules, with the subject and the hosts presenting each certificate as evidence.
+func certificateFindings(certs []*viz.CertificateInfo, rs *findings.RuleSet) []*findings.Finding {
+	var results []*findings.Finding
+
+	for _, c := range certs {
+		evidence := []string{"subject " + c.Subject}
+		if len(c.Hosts) > 0 {
+			evidence = append(evidence, "presented by "+strings.Join(c.Hosts, ", "))
+		}
+
+		report := func(id, detail string) {
+			if rule := rs.Rule(id); rule != nil {
+				e := append(append([]string{}, evidence...), detail)
+				results = append(results, findings.NewFinding(rule, string(oam.TLSCertificate), c.SerialNumber, e))
+			}
+		}
+		if c.Expired {
+			report(findings.CertificateExpiredID, "expired on "+formatCertTime(c.NotAfter))
+		}
+		if c.ExpiringSoon {
+			report(findings.CertificateExpiringSoonID, "expires on "+formatCertTime(c.NotAfter))
+		}
+		if c.SelfSigned {
+			report(findings.CertificateSelfSignedID, "issued by "+c.Issuer)
+		}
+	}
+	return results
+}
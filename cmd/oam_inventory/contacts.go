@@ -0,0 +1,291 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	"github.com/owasp-amass/oam-tools/viz"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/contact"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/org"
+	"github.com/owasp-amass/open-asset-model/people"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+)
+
+// The relations linking a contact record to its details
+var contactDetailRels = []string{"organization", "person", "email", "phone", "location"}
+
+// The relations linking a registration record to the contacts of the registered resource.
+// Registrar and abuse contacts are left out, since they are shared by unrelated registrations.
+var contactRoleRels = []string{"registrant_contact", "admin_contact", "technical_contact", "billing_contact", "registrant"}
+
+// ContactEntity is a cluster of contact details connected by the registrations that share them.
+type ContactEntity struct {
+	Organizations []string `json:"organizations"`
+	Persons       []string `json:"persons"`
+	Emails        []string `json:"emails"`
+	Phones        []string `json:"phones"`
+	Locations     []string `json:"locations"`
+	Domains       []string `json:"domains"`
+	Netblocks     []string `json:"netblocks"`
+	ASNs          []string `json:"asns"`
+	// OutOfScope lists the domains tied to the entity that do not belong to the provided domains
+	OutOfScope []string `json:"out_of_scope_domains"`
+}
+
+// valueSet keeps the original case of the contact details.
+type valueSet map[string]struct{}
+
+func (s valueSet) insert(v string) {
+	s[v] = struct{}{}
+}
+
+type contactCluster struct {
+	orgs, persons, emails, phones, locations valueSet
+	domains, netblocks, asns                 valueSet
+}
+
+func newContactCluster() *contactCluster {
+	return &contactCluster{
+		orgs:      make(valueSet),
+		persons:   make(valueSet),
+		emails:    make(valueSet),
+		phones:    make(valueSet),
+		locations: make(valueSet),
+		domains:   make(valueSet),
+		netblocks: make(valueSet),
+		asns:      make(valueSet),
+	}
+}
+
+func (c *contactCluster) entity(domains []string) *ContactEntity {
+	sorted := func(set valueSet) []string {
+		list := make([]string, 0, len(set))
+		for v := range set {
+			list = append(list, v)
+		}
+
+		sort.Strings(list)
+		return list
+	}
+
+	e := &ContactEntity{
+		Organizations: sorted(c.orgs),
+		Persons:       sorted(c.persons),
+		Emails:        sorted(c.emails),
+		Phones:        sorted(c.phones),
+		Locations:     sorted(c.locations),
+		Domains:       sorted(c.domains),
+		Netblocks:     sorted(c.netblocks),
+		ASNs:          sorted(c.asns),
+		OutOfScope:    []string{},
+	}
+
+	for _, d := range e.Domains {
+		if !requests.DomainNameInScope(d, domains) {
+			e.OutOfScope = append(e.OutOfScope, d)
+		}
+	}
+	return e
+}
+
+// ContactInventory returns the organizations, people, email addresses, phones and locations reachable
+// from the scope, clustered by the contact details shared across registrations. The registrations tied
+// to each cluster can reveal domains registered by the same entity outside the provided domains.
+func ContactInventory(g *graph.Graph, domains []string, since time.Time) []*ContactEntity {
+	var seeds []*types.Asset
+
+	if assets, err := g.DB.FindByType(oam.DomainRecord, since); err == nil {
+		for _, a := range assets {
+			if dr, ok := a.Asset.(*oamreg.DomainRecord); ok && requests.DomainNameInScope(dr.Domain, domains) {
+				seeds = append(seeds, requests.OutgoingAssets(g, a, since, contactRoleRels...)...)
+			}
+		}
+	}
+
+	netblocks, asns := scopeNetworks(g, domains, since)
+	for _, a := range append(netblocks, asns...) {
+		for _, reg := range requests.OutgoingAssets(g, a, since, "registration") {
+			seeds = append(seeds, requests.OutgoingAssets(g, reg, since, contactRoleRels...)...)
+		}
+	}
+
+	// Email addresses within the scope can tie contact records to the target
+	if assets, err := g.DB.FindByType(oam.EmailAddress, since); err == nil {
+		for _, a := range assets {
+			if email, ok := a.Asset.(*contact.EmailAddress); ok && requests.DomainNameInScope(email.Domain, domains) {
+				seeds = append(seeds, a)
+			}
+		}
+	}
+
+	var entities []*ContactEntity
+	visited := make(map[string]struct{})
+	for _, seed := range seeds {
+		if _, found := visited[seed.ID]; found {
+			continue
+		}
+
+		c := newContactCluster()
+		collectContactCluster(g, seed, c, visited, since)
+		if len(c.domains) == 0 && len(c.netblocks) == 0 && len(c.asns) == 0 {
+			continue
+		}
+		entities = append(entities, c.entity(domains))
+	}
+
+	sort.Slice(entities, func(i, j int) bool {
+		if len(entities[i].Domains) != len(entities[j].Domains) {
+			return len(entities[i].Domains) > len(entities[j].Domains)
+		}
+		return strings.Join(entities[i].Organizations, ",") < strings.Join(entities[j].Organizations, ",")
+	})
+	return entities
+}
+
+// collectContactCluster walks from contact records to their details and back to other contact records
+// sharing those details, adding the details and the registered resources to the cluster.
+func collectContactCluster(g *graph.Graph, start *types.Asset, c *contactCluster, visited map[string]struct{}, since time.Time) {
+	queue := []*types.Asset{start}
+
+	for len(queue) > 0 {
+		a := queue[0]
+		queue = queue[1:]
+
+		if _, found := visited[a.ID]; found {
+			continue
+		}
+		visited[a.ID] = struct{}{}
+
+		if _, ok := a.Asset.(*contact.ContactRecord); ok {
+			owners := requests.IncomingAssets(g, a, since, contactRoleRels...)
+			if len(owners) == 0 {
+				continue
+			}
+
+			for _, owner := range owners {
+				addRegisteredResource(g, owner, c, since)
+			}
+			queue = append(queue, requests.OutgoingAssets(g, a, since, contactDetailRels...)...)
+			continue
+		}
+
+		if !addContactDetail(a, c) {
+			continue
+		}
+		queue = append(queue, requests.IncomingAssets(g, a, since, contactDetailRels...)...)
+	}
+}
+
+// addContactDetail adds the detail to the cluster, returning false for details hidden by privacy services.
+func addContactDetail(a *types.Asset, c *contactCluster) bool {
+	var value string
+	var set valueSet
+
+	switch v := a.Asset.(type) {
+	case *org.Organization:
+		value, set = v.Name, c.orgs
+	case *people.Person:
+		value, set = v.FullName, c.persons
+	case *contact.EmailAddress:
+		value, set = strings.ToLower(v.Address), c.emails
+	case *contact.Phone:
+		value, set = v.E164, c.phones
+		if value == "" {
+			value = v.Raw
+		}
+	case *contact.Location:
+		value, set = v.Address, c.locations
+	default:
+		return false
+	}
+
+	if value == "" || viz.PrivacyProtected(value) {
+		return false
+	}
+	set.insert(value)
+	return true
+}
+
+func addRegisteredResource(g *graph.Graph, record *types.Asset, c *contactCluster, since time.Time) {
+	switch v := record.Asset.(type) {
+	case *oamreg.DomainRecord:
+		if v.Domain != "" {
+			c.domains.insert(strings.ToLower(v.Domain))
+		}
+	case *oamreg.IPNetRecord:
+		for _, nb := range requests.IncomingAssets(g, record, since, "registration") {
+			if n, ok := nb.Asset.(*network.Netblock); ok {
+				c.netblocks.insert(n.CIDR.String())
+			}
+		}
+	case *oamreg.AutnumRecord:
+		c.asns.insert(strconv.Itoa(v.Number))
+	}
+}
+
+// FprintContacts writes each entity with its contact details and the registrations tied to it.
+func FprintContacts(out io.Writer, entities []*ContactEntity) {
+	if len(entities) == 0 {
+		fmt.Fprintln(out, blue("No contacts were found for the scope"))
+		return
+	}
+
+	for i, e := range entities {
+		title := strings.Join(e.Organizations, ", ")
+		if title == "" {
+			title = strings.Join(e.Persons, ", ")
+		}
+		fmt.Fprintf(out, "%s %s\n", blue("Entity "+strconv.Itoa(i+1)+":"), green(title))
+
+		fprintField(out, "Persons", strings.Join(e.Persons, ", "))
+		fprintField(out, "Emails", strings.Join(e.Emails, ", "))
+		fprintField(out, "Phones", strings.Join(e.Phones, ", "))
+		fprintField(out, "Locations", strings.Join(e.Locations, "; "))
+		fprintField(out, "Domains", strings.Join(e.Domains, ", "))
+		fprintField(out, "Netblocks", strings.Join(e.Netblocks, ", "))
+		fprintField(out, "ASNs", strings.Join(e.ASNs, ", "))
+
+		if len(e.OutOfScope) > 0 {
+			fmt.Fprintf(out, "\t%s %s\n", blue(fmt.Sprintf("%-9s", "New")),
+				r.Sprint("domains outside the scope: "+strings.Join(e.OutOfScope, ", ")))
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// contactRecords returns the entities as CSV records, starting with the header.
+func contactRecords(entities []*ContactEntity) [][]string {
+	records := [][]string{{
+		"entity", "organizations", "persons", "emails", "phones", "locations",
+		"domains", "netblocks", "asns", "out_of_scope_domains",
+	}}
+
+	for i, e := range entities {
+		records = append(records, []string{
+			strconv.Itoa(i + 1),
+			strings.Join(e.Organizations, "; "),
+			strings.Join(e.Persons, "; "),
+			strings.Join(e.Emails, " "),
+			strings.Join(e.Phones, " "),
+			strings.Join(e.Locations, "; "),
+			strings.Join(e.Domains, " "),
+			strings.Join(e.Netblocks, " "),
+			strings.Join(e.ASNs, " "),
+			strings.Join(e.OutOfScope, " "),
+		})
+	}
+	return records
+}
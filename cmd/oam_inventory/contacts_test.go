@@ -0,0 +1,113 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/open-asset-model/contact"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/org"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContactInventory(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+
+	newContact := func(record *types.Asset, rel string, orgName string, emails ...string) {
+		cr, err := g.DB.Create(record, rel, &contact.ContactRecord{DiscoveredAt: "https://rdap.example/" + record.ID + "/" + rel})
+		assert.Nil(t, err)
+
+		if orgName != "" {
+			_, err = g.DB.Create(cr, "organization", &org.Organization{Name: orgName})
+			assert.Nil(t, err)
+		}
+		for _, email := range emails {
+			user, dom, _ := strings.Cut(email, "@")
+
+			_, err = g.DB.Create(cr, "email", &contact.EmailAddress{Address: email, Username: user, Domain: dom})
+			assert.Nil(t, err)
+		}
+	}
+	newDomain := func(name string) *types.Asset {
+		dr, err := g.DB.Create(nil, "", &oamreg.DomainRecord{Domain: name, Name: name})
+		assert.Nil(t, err)
+		return dr
+	}
+
+	// The target registers a brand domain with the same organization, and a shop with the same email
+	dr := newDomain("contacttest.domain")
+	newContact(dr, "registrant_contact", "Contacttest Corp", "hostmaster@contacttest.domain")
+	newContact(dr, "technical_contact", "REDACTED FOR PRIVACY", "hostmaster@contacttest.domain")
+	newContact(dr, "registrar_contact", "Contacttest Registrar")
+	newContact(newDomain("contacttest-brand.io"), "registrant_contact", "Contacttest Corp")
+	newContact(newDomain("contacttest-shop.com"), "admin_contact", "", "hostmaster@contacttest.domain")
+
+	// A privacy service and a registrar shared with unrelated registrations do not join the entity
+	newContact(newDomain("contacttest-private.net"), "registrant_contact", "REDACTED FOR PRIVACY")
+	newContact(newDomain("contacttest-unrelated.org"), "registrar_contact", "Contacttest Registrar")
+
+	// An in-scope email address used by a registration outside the scope
+	newContact(newDomain("contacttest-legacy.org"), "registrant_contact", "Contacttest Legacy", "dns@contacttest.domain")
+
+	// The registrant of the netblock containing an in-scope address
+	_, err := g.UpsertA(ctx, "www.contacttest.domain", "93.184.221.150")
+	assert.Nil(t, err)
+	root, err := g.DB.Create(nil, "", &domain.FQDN{Name: "contacttest.domain"})
+	assert.Nil(t, err)
+	www, err := g.DB.FindByContent(&domain.FQDN{Name: "www.contacttest.domain"}, time.Time{})
+	if assert.Nil(t, err) && assert.NotEmpty(t, www) {
+		_, err = g.DB.Link(root, "node", www[0])
+		assert.Nil(t, err)
+	}
+	prefix := netip.MustParsePrefix("93.184.221.0/24")
+	nb, err := g.DB.Create(nil, "", &network.Netblock{CIDR: prefix, Type: "IPv4"})
+	assert.Nil(t, err)
+	ips, err := g.DB.FindByContent(&network.IPAddress{Address: netip.MustParseAddr("93.184.221.150"), Type: "IPv4"}, time.Time{})
+	if assert.Nil(t, err) && assert.NotEmpty(t, ips) {
+		_, err = g.DB.Link(nb, "contains", ips[0])
+		assert.Nil(t, err)
+	}
+	rec, err := g.DB.Create(nb, "registration", &oamreg.IPNetRecord{CIDR: prefix, Handle: "NET-CONTACTTEST"})
+	assert.Nil(t, err)
+	newContact(rec, "registrant", "Contacttest Hosting")
+
+	entities := ContactInventory(g, []string{"contacttest.domain"}, time.Time{})
+
+	if assert.Len(t, entities, 3) {
+		e := entities[0]
+		assert.Equal(t, []string{"Contacttest Corp"}, e.Organizations)
+		assert.Equal(t, []string{"hostmaster@contacttest.domain"}, e.Emails)
+		assert.Equal(t, []string{"contacttest-brand.io", "contacttest-shop.com", "contacttest.domain"}, e.Domains)
+		assert.Equal(t, []string{"contacttest-brand.io", "contacttest-shop.com"}, e.OutOfScope)
+		assert.Empty(t, e.Netblocks)
+
+		e = entities[1]
+		assert.Equal(t, []string{"Contacttest Legacy"}, e.Organizations)
+		assert.Equal(t, []string{"dns@contacttest.domain"}, e.Emails)
+		assert.Equal(t, []string{"contacttest-legacy.org"}, e.OutOfScope)
+
+		e = entities[2]
+		assert.Equal(t, []string{"Contacttest Hosting"}, e.Organizations)
+		assert.Equal(t, []string{"93.184.221.0/24"}, e.Netblocks)
+		assert.Empty(t, e.Domains)
+	}
+
+	if records := contactRecords(entities); assert.Len(t, records, 4) {
+		assert.Equal(t, "entity", records[0][0])
+		assert.Equal(t, "contacttest-brand.io contacttest-shop.com", records[1][9])
+	}
+
+	assert.Empty(t, ContactInventory(g, []string{"contacttest-missing.domain"}, time.Time{}))
+}
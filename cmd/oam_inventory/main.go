@@ -0,0 +1,353 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+// oam_inventory: Inventory the certificates, services and registrations collected for the scope
+//
+//	+----------------------------------------------------------------------------+
+//	| ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░  OWASP Amass  ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░ |
+//	+----------------------------------------------------------------------------+
+//	|      .+++:.            :                             .+++.                 |
+//	|    +W@@@@@@8        &+W@#               o8W8:      +W@@@@@@#.   oW@@@W#+   |
+//	|   &@#+   .o@##.    .@@@o@W.o@@o       :@@#&W8o    .@#:  .:oW+  .@#+++&#&   |
+//	|  +@&        &@&     #@8 +@W@&8@+     :@W.   +@8   +@:          .@8         |
+//	|  8@          @@     8@o  8@8  WW    .@W      W@+  .@W.          o@#:       |
+//	|  WW          &@o    &@:  o@+  o@+   #@.      8@o   +W@#+.        +W@8:     |
+//	|  #@          :@W    &@+  &@+   @8  :@o       o@o     oW@@W+        oW@8    |
+//	|  o@+          @@&   &@+  &@+   #@  &@.      .W@W       .+#@&         o@W.  |
+//	|   WW         +@W@8. &@+  :&    o@+ #@      :@W&@&         &@:  ..     :@o  |
+//	|   :@W:      o@# +Wo &@+        :W: +@W&o++o@W. &@&  8@#o+&@W.  #@:    o@+  |
+//	|    :W@@WWWW@@8       +              :&W@@@@&    &W  .o#@@W&.   :W@WWW@@&   |
+//	|      +o&&&&+.                                                    +oooo.    |
+//	+----------------------------------------------------------------------------+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caffix/stringset"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/config/config"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/findings"
+	"github.com/owasp-amass/oam-tools/viz"
+)
+
+const (
+	timeFormat = "01/02 15:04:05 2006 MST"
+	usageMsg   = "-certs|-services|-whois|-contacts [options] -d domain"
+)
+
+var (
+	// Colors used to ease the reading of program output
+	g      = color.New(color.FgHiGreen)
+	r      = color.New(color.FgHiRed)
+	yellow = color.New(color.FgHiYellow).SprintFunc()
+	green  = color.New(color.FgHiGreen).SprintFunc()
+	blue   = color.New(color.FgHiBlue).SprintFunc()
+)
+
+type inventoryArgs struct {
+	Domains  *stringset.Set
+	Expiring int
+	Since    string
+	Modes    struct {
+		Certs    bool
+		Contacts bool
+		Services bool
+		Whois    bool
+	}
+	Filters struct {
+		Ports   string
+		Service string
+	}
+	Options struct {
+		NoColor bool
+		Silent  bool
+	}
+	Filepaths struct {
+		ConfigFile  string
+		CSVOutput   string
+		Directory   string
+		Domains     string
+		JSONOutput  string
+		SARIFOutput string
+	}
+}
+
+func main() {
+	var args inventoryArgs
+	var help1, help2 bool
+	invCommand := flag.NewFlagSet("inventory", flag.ContinueOnError)
+
+	args.Domains = stringset.New()
+	defer args.Domains.Close()
+
+	invBuf := new(bytes.Buffer)
+	invCommand.SetOutput(invBuf)
+
+	invCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	invCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	invCommand.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
+	invCommand.BoolVar(&args.Modes.Certs, "certs", false, "List the TLS certificates presented by or issued for the scope")
+	invCommand.BoolVar(&args.Modes.Contacts, "contacts", false, "List the organizations and contacts reachable from the scope, clustered by shared details")
+	invCommand.BoolVar(&args.Modes.Services, "services", false, "List the services found on the endpoints within the scope")
+	invCommand.BoolVar(&args.Modes.Whois, "whois", false, "List the registrations of the domains, netblocks and ASNs within the scope")
+	invCommand.StringVar(&args.Filters.Ports, "port", "", "Include only the services on the ports separated by commas")
+	invCommand.StringVar(&args.Filters.Service, "service", "", "Include only the services matching the protocol, server or banner")
+	invCommand.IntVar(&args.Expiring, "expiring", 30, "Number of days before expiration that a certificate or domain is flagged")
+	invCommand.StringVar(&args.Since, "since", "", "Include only assets validated after (format: "+timeFormat+")")
+	invCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	invCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	invCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file")
+	invCommand.StringVar(&args.Filepaths.CSVOutput, "csv", "", "Path to the CSV output file")
+	invCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
+	invCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing root domain names")
+	invCommand.StringVar(&args.Filepaths.JSONOutput, "json", "", "Path to the JSON output file")
+	invCommand.StringVar(&args.Filepaths.SARIFOutput, "sarif", "", "Path to the SARIF 2.1.0 output file containing the certificate issues (-certs only)")
+
+	var usage = func() {
+		g.Fprintf(color.Error, "Usage: %s %s\n\n", path.Base(os.Args[0]), usageMsg)
+		invCommand.PrintDefaults()
+		g.Fprintln(color.Error, invBuf.String())
+	}
+
+	if len(os.Args) < 2 {
+		usage()
+		return
+	}
+	if err := invCommand.Parse(os.Args[1:]); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 {
+		usage()
+		return
+	}
+	if args.Options.NoColor {
+		color.NoColor = true
+	}
+	if args.Options.Silent {
+		color.Output = io.Discard
+		color.Error = io.Discard
+	}
+	if modes := countModes(&args); modes == 0 {
+		usage()
+		return
+	} else if modes > 1 {
+		r.Fprintln(color.Error, "Only one inventory mode can be selected at a time")
+		os.Exit(1)
+	}
+	if args.Filepaths.SARIFOutput != "" && !args.Modes.Certs {
+		r.Fprintln(color.Error, "The SARIF output is only supported by the -certs mode")
+		os.Exit(1)
+	}
+
+	filter, err := parseServiceFilter(&args)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if args.Filepaths.Domains != "" {
+		list, err := config.GetListFromFile(args.Filepaths.Domains)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to parse the domain names file: %v\n", err)
+			os.Exit(1)
+		}
+		args.Domains.InsertMany(list...)
+	}
+
+	var start time.Time
+	if args.Since != "" {
+		start, err = time.Parse(timeFormat, args.Since)
+		if err != nil {
+			r.Fprintf(color.Error, "%s is not in the correct format: %s\n", args.Since, timeFormat)
+			os.Exit(1)
+		}
+		start = start.UTC()
+	}
+
+	cfg := config.NewConfig()
+	// Check if a configuration file was provided, and if so, load the settings
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err == nil {
+		if args.Filepaths.Directory == "" {
+			args.Filepaths.Directory = cfg.Dir
+		}
+		if args.Domains.Len() == 0 {
+			args.Domains.InsertMany(cfg.Domains()...)
+		}
+	} else if args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	if args.Domains.Len() == 0 {
+		r.Fprintln(color.Error, "No root domain names were provided")
+		os.Exit(1)
+	}
+	// Connect with the graph database containing the enumeration data
+	db := openGraphDatabase(args.Filepaths.Directory, cfg)
+	if db == nil {
+		r.Fprintln(color.Error, "Failed to connect with the database")
+		os.Exit(1)
+	}
+
+	domains := args.Domains.Slice()
+	if args.Modes.Certs {
+		certs := viz.CertificateInventory(db, domains, start, time.Now(), time.Duration(args.Expiring)*24*time.Hour)
+
+		FprintCertificates(color.Output, certs)
+		writeOutputFiles(&args, certs, certificateRecords(certs))
+		if args.Filepaths.SARIFOutput != "" {
+			if err := writeSARIFFile(args.Filepaths.SARIFOutput, certs); err != nil {
+				r.Fprintf(color.Error, "Failed to write the SARIF output file: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+	if args.Modes.Services {
+		services := ServiceInventory(db, domains, filter, start)
+
+		FprintServices(color.Output, services)
+		writeOutputFiles(&args, services, serviceRecords(services))
+	}
+	if args.Modes.Whois {
+		report := RegistrationInventory(db, domains, start, time.Now(), time.Duration(args.Expiring)*24*time.Hour)
+
+		FprintRegistrations(color.Output, report)
+		writeOutputFiles(&args, report, registrationRecords(report))
+	}
+	if args.Modes.Contacts {
+		entities := ContactInventory(db, domains, start)
+
+		FprintContacts(color.Output, entities)
+		writeOutputFiles(&args, entities, contactRecords(entities))
+	}
+}
+
+func countModes(args *inventoryArgs) int {
+	var count int
+
+	for _, selected := range []bool{args.Modes.Certs, args.Modes.Contacts, args.Modes.Services, args.Modes.Whois} {
+		if selected {
+			count++
+		}
+	}
+	return count
+}
+
+func parseServiceFilter(args *inventoryArgs) (*ServiceFilter, error) {
+	filter := &ServiceFilter{Service: strings.TrimSpace(args.Filters.Service)}
+
+	for _, p := range strings.Split(args.Filters.Ports, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+
+		port, err := strconv.Atoi(p)
+		if err != nil || port < 0 || port > 65535 {
+			return nil, fmt.Errorf("%s is not a valid port number", p)
+		}
+		filter.Ports = append(filter.Ports, port)
+	}
+	return filter, nil
+}
+
+// writeOutputFiles saves the inventory to the JSON and CSV output files requested on the command line.
+func writeOutputFiles(args *inventoryArgs, v any, records [][]string) {
+	if args.Filepaths.JSONOutput != "" {
+		if err := writeJSONFile(args.Filepaths.JSONOutput, v); err != nil {
+			r.Fprintf(color.Error, "Failed to write the JSON output file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if args.Filepaths.CSVOutput != "" {
+		if err := writeCSVFile(args.Filepaths.CSVOutput, records); err != nil {
+			r.Fprintf(color.Error, "Failed to write the CSV output file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func writeJSONFile(path string, v any) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeSARIFFile saves the certificate issues as results of the certificate rules from the default rule pack.
+func writeSARIFFile(path string, certs []*viz.CertificateInfo) error {
+	rs, err := findings.DefaultRules()
+	if err != nil {
+		return err
+	}
+
+	var rules []*findings.Rule
+	for _, id := range []string{findings.CertificateExpiredID, findings.CertificateExpiringSoonID, findings.CertificateSelfSignedID} {
+		if rule := rs.Rule(id); rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return findings.WriteSARIF(f, "oam_inventory", rules, certificateFindings(certs, rs))
+}
+
+func writeCSVFile(path string, records [][]string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.WriteAll(records); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func openGraphDatabase(dir string, cfg *config.Config) *graph.Graph {
+	// Add the local database settings to the configuration
+	cfg.GraphDBs = append(cfg.GraphDBs, cfg.LocalDatabaseSettings(cfg.GraphDBs))
+
+	for _, db := range cfg.GraphDBs {
+		if db.Primary {
+			var g *graph.Graph
+
+			if db.System == "local" {
+				g = graph.NewGraph(db.System, filepath.Join(config.OutputDirectory(cfg.Dir), "amass.sqlite"), db.Options)
+			} else {
+				connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s", db.Host, db.Port, db.Username, db.Password, db.DBName)
+				g = graph.NewGraph(db.System, connStr, db.Options)
+			}
+
+			if g != nil {
+				return g
+			}
+			break
+		}
+	}
+	return nil
+}
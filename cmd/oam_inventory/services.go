@@ -0,0 +1,204 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	"github.com/owasp-amass/oam-tools/viz"
+	oam "github.com/owasp-amass/open-asset-model"
+	oamcert "github.com/owasp-amass/open-asset-model/certificate"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/fingerprint"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/service"
+)
+
+// The number of banner characters shown in the text output
+const maxBannerLen = 60
+
+// ServiceInfo describes a network endpoint or socket address within the scope and the service found on it.
+type ServiceInfo struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	// Service is the identifier of the service asset, empty when no service was found on the port
+	Service      string   `json:"service,omitempty"`
+	Banner       string   `json:"banner,omitempty"`
+	Server       string   `json:"server,omitempty"`
+	Fingerprints []string `json:"fingerprints,omitempty"`
+	Certificates []string `json:"certificates,omitempty"`
+}
+
+// ServiceFilter selects the services kept by the ServiceInventory. Zero values match everything.
+type ServiceFilter struct {
+	Ports []int
+	// Service is matched against the protocol, server header and banner, ignoring case
+	Service string
+}
+
+func (f *ServiceFilter) match(s *ServiceInfo) bool {
+	if len(f.Ports) > 0 {
+		var found bool
+
+		for _, p := range f.Ports {
+			if p == s.Port {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.Service != "" {
+		want := strings.ToLower(f.Service)
+
+		if !strings.EqualFold(s.Protocol, want) && !strings.Contains(strings.ToLower(s.Server), want) &&
+			!strings.Contains(strings.ToLower(s.Banner), want) {
+			return false
+		}
+	}
+	return true
+}
+
+// ServiceInventory returns the endpoints within the scope along with the services, fingerprints
+// and certificates found on them.
+func ServiceInventory(g *graph.Graph, domains []string, filter *ServiceFilter, since time.Time) []*ServiceInfo {
+	var results []*ServiceInfo
+
+	if endpoints, err := g.DB.FindByType(oam.NetworkEndpoint, since); err == nil {
+		for _, a := range endpoints {
+			if ep, ok := a.Asset.(*domain.NetworkEndpoint); ok && requests.DomainNameInScope(ep.Name, domains) {
+				results = append(results, endpointServices(g, a, ep.Name, ep.Port, ep.Protocol, filter, since)...)
+			}
+		}
+	}
+
+	if sockets, err := g.DB.FindByType(oam.SocketAddress, since); err == nil {
+		for _, a := range sockets {
+			if sa, ok := a.Asset.(*network.SocketAddress); ok && viz.AddressInScope(g, sa.IPAddress, domains, since) {
+				results = append(results, endpointServices(g, a, sa.IPAddress.String(), sa.Port, sa.Protocol, filter, since)...)
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Host != results[j].Host {
+			return results[i].Host < results[j].Host
+		}
+		if results[i].Port != results[j].Port {
+			return results[i].Port < results[j].Port
+		}
+		return results[i].Service < results[j].Service
+	})
+	return results
+}
+
+func endpointServices(g *graph.Graph, endpoint *types.Asset, host string, port int, proto string, filter *ServiceFilter, since time.Time) []*ServiceInfo {
+	var results []*ServiceInfo
+
+	services := requests.OutgoingAssets(g, endpoint, since, "service")
+	if len(services) == 0 {
+		services = []*types.Asset{nil}
+	}
+
+	for _, a := range services {
+		info := &ServiceInfo{
+			Host:     host,
+			Port:     port,
+			Protocol: proto,
+		}
+
+		if a != nil {
+			if serv, ok := a.Asset.(*service.Service); ok {
+				info.Service = serv.Identifier
+				info.Banner = serv.Banner
+				if values := serv.Headers["Server"]; len(values) > 0 {
+					info.Server = values[0]
+				}
+			}
+
+			for _, to := range requests.OutgoingAssets(g, a, since, "fingerprint", "certificate") {
+				switch v := to.Asset.(type) {
+				case *fingerprint.Fingerprint:
+					info.Fingerprints = append(info.Fingerprints, v.Type+":"+v.Value)
+				case *oamcert.TLSCertificate:
+					info.Certificates = append(info.Certificates, v.SerialNumber)
+				}
+			}
+			sort.Strings(info.Fingerprints)
+			sort.Strings(info.Certificates)
+		}
+
+		if filter == nil || filter.match(info) {
+			results = append(results, info)
+		}
+	}
+	return results
+}
+
+// FprintServices writes a line for each endpoint followed by the details of its service.
+func FprintServices(out io.Writer, services []*ServiceInfo) {
+	if len(services) == 0 {
+		fmt.Fprintln(out, blue("No services were found for the scope"))
+		return
+	}
+
+	for _, s := range services {
+		proto := s.Protocol
+		if proto == "" {
+			proto = "unknown"
+		}
+		fmt.Fprintf(out, "%s %s\n", green(s.Host+":"+strconv.Itoa(s.Port)), blue("("+proto+")"))
+
+		fprintField(out, "Service", s.Service)
+		fprintField(out, "Server", s.Server)
+		fprintField(out, "Banner", shortBanner(s.Banner))
+		fprintField(out, "Prints", strings.Join(s.Fingerprints, ", "))
+		fprintField(out, "Certs", strings.Join(s.Certificates, ", "))
+	}
+}
+
+// shortBanner returns the first line of the banner, truncated for the text output.
+func shortBanner(banner string) string {
+	banner, _, _ = strings.Cut(strings.TrimSpace(banner), "\n")
+	banner = strings.TrimSpace(banner)
+
+	if runes := []rune(banner); len(runes) > maxBannerLen {
+		banner = string(runes[:maxBannerLen]) + "..."
+	}
+	return banner
+}
+
+// serviceRecords returns the services as CSV records, starting with the header.
+func serviceRecords(services []*ServiceInfo) [][]string {
+	records := [][]string{{
+		"host", "port", "protocol", "service", "server", "banner", "fingerprints", "certificates",
+	}}
+
+	for _, s := range services {
+		records = append(records, []string{
+			s.Host,
+			strconv.Itoa(s.Port),
+			s.Protocol,
+			s.Service,
+			s.Server,
+			s.Banner,
+			strings.Join(s.Fingerprints, " "),
+			strings.Join(s.Certificates, " "),
+		})
+	}
+	return records
+}
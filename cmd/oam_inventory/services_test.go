@@ -0,0 +1,126 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/graph"
+	oamcert "github.com/owasp-amass/open-asset-model/certificate"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/fingerprint"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceInventory(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+
+	_, err := g.UpsertA(ctx, "www.svctest.domain", "93.184.216.130")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(ctx, "db.svctest.domain", "edge.svctest-cloud.net")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "edge.svctest-cloud.net", "93.184.216.131")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "www.svctest-other.com", "93.184.216.132")
+	assert.Nil(t, err)
+
+	// A web server with a fingerprint and a certificate, and an SSH port without a service
+	www, err := g.DB.FindByContent(&domain.FQDN{Name: "www.svctest.domain"}, time.Time{})
+	if assert.Nil(t, err) && assert.NotEmpty(t, www) {
+		ep, err := g.DB.Create(www[0], "port", &domain.NetworkEndpoint{
+			Address: "www.svctest.domain:443", Name: "www.svctest.domain", Port: 443, Protocol: "https"})
+		assert.Nil(t, err)
+		svc, err := g.DB.Create(ep, "service", &service.Service{
+			Identifier: "svctest-web",
+			Banner:     "HTTP/1.1 200 OK\r\nServer: nginx/1.25.3",
+			Headers:    map[string][]string{"Server": {"nginx/1.25.3"}},
+		})
+		assert.Nil(t, err)
+		_, err = g.DB.Create(svc, "fingerprint", &fingerprint.Fingerprint{Type: "jarm", Value: "svctest"})
+		assert.Nil(t, err)
+		_, err = g.DB.Create(svc, "certificate", &oamcert.TLSCertificate{SerialNumber: "svctest01"})
+		assert.Nil(t, err)
+
+		_, err = g.DB.Create(www[0], "port", &domain.NetworkEndpoint{
+			Address: "www.svctest.domain:22", Name: "www.svctest.domain", Port: 22, Protocol: "ssh"})
+		assert.Nil(t, err)
+	}
+
+	// A database on the address an in-scope alias resolves to, and one on an address outside the scope
+	for _, addr := range []string{"93.184.216.131", "93.184.216.132"} {
+		ip := netip.MustParseAddr(addr)
+		sa, err := g.DB.Create(nil, "", &network.SocketAddress{
+			Address: netip.AddrPortFrom(ip, 3306), IPAddress: ip, Port: 3306, Protocol: "mysql"})
+		assert.Nil(t, err)
+		_, err = g.DB.Create(sa, "service", &service.Service{Identifier: "svctest-db-" + addr, Banner: "5.7.44 MySQL Community Server"})
+		assert.Nil(t, err)
+	}
+
+	// An endpoint of a name outside the scope
+	other, err := g.DB.FindByContent(&domain.FQDN{Name: "www.svctest-other.com"}, time.Time{})
+	if assert.Nil(t, err) && assert.NotEmpty(t, other) {
+		_, err = g.DB.Create(other[0], "port", &domain.NetworkEndpoint{
+			Address: "www.svctest-other.com:443", Name: "www.svctest-other.com", Port: 443, Protocol: "https"})
+		assert.Nil(t, err)
+	}
+
+	domains := []string{"svctest.domain"}
+	services := ServiceInventory(g, domains, nil, time.Time{})
+
+	if assert.Len(t, services, 3) {
+		s := services[0]
+		assert.Equal(t, "93.184.216.131", s.Host)
+		assert.Equal(t, 3306, s.Port)
+		assert.Equal(t, "svctest-db-93.184.216.131", s.Service)
+
+		s = services[1]
+		assert.Equal(t, "www.svctest.domain", s.Host)
+		assert.Equal(t, 22, s.Port)
+		assert.Empty(t, s.Service)
+
+		s = services[2]
+		assert.Equal(t, 443, s.Port)
+		assert.Equal(t, "svctest-web", s.Service)
+		assert.Equal(t, "nginx/1.25.3", s.Server)
+		assert.Equal(t, []string{"jarm:svctest"}, s.Fingerprints)
+		assert.Equal(t, []string{"svctest01"}, s.Certificates)
+	}
+
+	for _, test := range []struct {
+		filter   *ServiceFilter
+		expected []string
+	}{
+		{&ServiceFilter{Ports: []int{22, 443}}, []string{"www.svctest.domain:22", "www.svctest.domain:443"}},
+		{&ServiceFilter{Service: "NGINX"}, []string{"www.svctest.domain:443"}},
+		{&ServiceFilter{Service: "mysql"}, []string{"93.184.216.131:3306"}},
+		{&ServiceFilter{Ports: []int{22}, Service: "mysql"}, nil},
+	} {
+		var hosts []string
+		for _, s := range ServiceInventory(g, domains, test.filter, time.Time{}) {
+			hosts = append(hosts, s.Host+":"+strconv.Itoa(s.Port))
+		}
+		assert.Equal(t, test.expected, hosts)
+	}
+
+	if records := serviceRecords(services); assert.Len(t, records, 4) {
+		assert.Equal(t, "host", records[0][0])
+		assert.Equal(t, []string{"www.svctest.domain", "443", "https", "svctest-web", "nginx/1.25.3",
+			"HTTP/1.1 200 OK\r\nServer: nginx/1.25.3", "jarm:svctest", "svctest01"}, records[3])
+	}
+}
+
+func TestShortBanner(t *testing.T) {
+	assert.Equal(t, "SSH-2.0-OpenSSH_9.6", shortBanner("  SSH-2.0-OpenSSH_9.6\r\nextra\n"))
+	assert.Equal(t, strings.Repeat("x", maxBannerLen)+"...", shortBanner(strings.Repeat("x", maxBannerLen+1)))
+	assert.Empty(t, shortBanner(""))
+}
@@ -0,0 +1,387 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caffix/stringset"
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	"github.com/owasp-amass/oam-tools/viz"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/contact"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/org"
+	"github.com/owasp-amass/open-asset-model/people"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+)
+
+// Types of the registration records included in the report
+const (
+	RegistrationDomain   = "domain"
+	RegistrationNetblock = "netblock"
+	RegistrationASN      = "asn"
+)
+
+// Layouts accepted for the dates found in registration records
+var registrationDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05Z", "2006-01-02 15:04:05", "2006-01-02"}
+
+// ContactDetails are the details linked to a ContactRecord.
+type ContactDetails struct {
+	Organization string   `json:"organization,omitempty"`
+	Persons      []string `json:"persons,omitempty"`
+	Emails       []string `json:"emails,omitempty"`
+	Phones       []string `json:"phones,omitempty"`
+	Locations    []string `json:"locations,omitempty"`
+}
+
+// RegistrationInfo describes the WHOIS / RDAP registration of a domain, netblock or autonomous system.
+type RegistrationInfo struct {
+	Type         string          `json:"type"`
+	Name         string          `json:"name"`
+	Handle       string          `json:"handle,omitempty"`
+	Registrar    string          `json:"registrar,omitempty"`
+	WhoisServer  string          `json:"whois_server,omitempty"`
+	Created      time.Time       `json:"created"`
+	Updated      time.Time       `json:"updated"`
+	Expires      time.Time       `json:"expires"`
+	Status       []string        `json:"status,omitempty"`
+	NameServers  []string        `json:"name_servers,omitempty"`
+	Registrant   *ContactDetails `json:"registrant,omitempty"`
+	Expired      bool            `json:"expired"`
+	ExpiringSoon bool            `json:"expiring_soon"`
+}
+
+// RegistrantDifference lists the values of a registrant detail that differ across the domains.
+type RegistrantDifference struct {
+	Field string `json:"field"`
+	// Values maps each value of the field to the domains registered with it
+	Values map[string][]string `json:"values"`
+}
+
+// RegistrationReport contains the registration records found for the scope.
+type RegistrationReport struct {
+	Records     []*RegistrationInfo     `json:"records"`
+	Differences []*RegistrantDifference `json:"differences"`
+}
+
+// RegistrationInventory returns the registrations of the domains within the scope, and of the netblocks
+// and autonomous systems containing the addresses that in-scope names resolve to. Domains expiring
+// within the window after now are flagged as expiring soon.
+func RegistrationInventory(g *graph.Graph, domains []string, since, now time.Time, window time.Duration) *RegistrationReport {
+	report := &RegistrationReport{
+		Records:     []*RegistrationInfo{},
+		Differences: []*RegistrantDifference{},
+	}
+
+	if assets, err := g.DB.FindByType(oam.DomainRecord, since); err == nil {
+		for _, a := range assets {
+			if dr, ok := a.Asset.(*oamreg.DomainRecord); ok && requests.DomainNameInScope(dr.Domain, domains) {
+				info := domainRegistration(g, a, dr, since)
+
+				info.Expired, info.ExpiringSoon = viz.ExpiryStatus(info.Expires, now, window)
+				report.Records = append(report.Records, info)
+			}
+		}
+	}
+
+	netblocks, asns := scopeNetworks(g, domains, since)
+	for _, nb := range netblocks {
+		for _, reg := range requests.OutgoingAssets(g, nb, since, "registration") {
+			if rec, ok := reg.Asset.(*oamreg.IPNetRecord); ok {
+				info := &RegistrationInfo{
+					Type:        RegistrationNetblock,
+					Name:        nb.Asset.Key(),
+					Handle:      rec.Handle,
+					WhoisServer: rec.WhoisServer,
+					Created:     parseRegistrationDate(rec.CreatedDate),
+					Updated:     parseRegistrationDate(rec.UpdatedDate),
+					Status:      rec.Status,
+					Registrant:  registrant(g, reg, "registrant", since),
+				}
+				report.Records = append(report.Records, info)
+			}
+		}
+	}
+	for _, as := range asns {
+		for _, reg := range requests.OutgoingAssets(g, as, since, "registration") {
+			if rec, ok := reg.Asset.(*oamreg.AutnumRecord); ok {
+				info := &RegistrationInfo{
+					Type:        RegistrationASN,
+					Name:        as.Asset.Key(),
+					Handle:      rec.Handle,
+					WhoisServer: rec.WhoisServer,
+					Created:     parseRegistrationDate(rec.CreatedDate),
+					Updated:     parseRegistrationDate(rec.UpdatedDate),
+					Status:      rec.Status,
+					Registrant:  registrant(g, reg, "registrant", since),
+				}
+				if info.Registrant == nil && rec.Name != "" {
+					info.Registrant = &ContactDetails{Organization: rec.Name}
+				}
+				report.Records = append(report.Records, info)
+			}
+		}
+	}
+
+	order := map[string]int{RegistrationDomain: 0, RegistrationNetblock: 1, RegistrationASN: 2}
+	sort.Slice(report.Records, func(i, j int) bool {
+		ri, rj := report.Records[i], report.Records[j]
+
+		if ri.Type != rj.Type {
+			return order[ri.Type] < order[rj.Type]
+		}
+		return ri.Name < rj.Name
+	})
+
+	report.Differences = registrantDifferences(report.Records)
+	return report
+}
+
+func domainRegistration(g *graph.Graph, a *types.Asset, dr *oamreg.DomainRecord, since time.Time) *RegistrationInfo {
+	info := &RegistrationInfo{
+		Type:        RegistrationDomain,
+		Name:        strings.ToLower(dr.Domain),
+		Handle:      dr.ID,
+		WhoisServer: dr.WhoisServer,
+		Created:     parseRegistrationDate(dr.CreatedDate),
+		Updated:     parseRegistrationDate(dr.UpdatedDate),
+		Expires:     parseRegistrationDate(dr.ExpirationDate),
+		Status:      dr.Status,
+		Registrant:  registrant(g, a, "registrant_contact", since),
+	}
+
+	if registrar := registrant(g, a, "registrar_contact", since); registrar != nil {
+		info.Registrar = registrar.Organization
+	}
+
+	for _, ns := range requests.OutgoingAssets(g, a, since, "name_server") {
+		if n, ok := ns.Asset.(*domain.FQDN); ok {
+			info.NameServers = append(info.NameServers, strings.ToLower(n.Name))
+		}
+	}
+	sort.Strings(info.NameServers)
+	return info
+}
+
+// scopeNetworks returns the netblocks containing addresses that in-scope names resolve to,
+// and the autonomous systems announcing those netblocks.
+func scopeNetworks(g *graph.Graph, domains []string, since time.Time) ([]*types.Asset, []*types.Asset) {
+	var netblocks, asns []*types.Asset
+
+	seen := make(map[string]struct{})
+	for _, addr := range viz.ScopeAddresses(g, domains, since) {
+		for _, nb := range requests.IncomingAssets(g, addr, since, "contains") {
+			if _, found := seen[nb.ID]; found {
+				continue
+			}
+			seen[nb.ID] = struct{}{}
+			netblocks = append(netblocks, nb)
+
+			for _, as := range requests.IncomingAssets(g, nb, since, "announces") {
+				if _, found := seen[as.ID]; !found {
+					seen[as.ID] = struct{}{}
+					asns = append(asns, as)
+				}
+			}
+		}
+	}
+	return netblocks, asns
+}
+
+// registrant returns the details of the first contact record linked to the registration by the relation.
+func registrant(g *graph.Graph, record *types.Asset, relation string, since time.Time) *ContactDetails {
+	for _, cr := range requests.OutgoingAssets(g, record, since, relation) {
+		if _, ok := cr.Asset.(*contact.ContactRecord); ok {
+			return contactDetails(g, cr, since)
+		}
+	}
+	return nil
+}
+
+// contactDetails collects the organization, people, email addresses, phones and locations of the contact record.
+func contactDetails(g *graph.Graph, cr *types.Asset, since time.Time) *ContactDetails {
+	details := &ContactDetails{}
+
+	for _, a := range requests.OutgoingAssets(g, cr, since, "organization", "person", "email", "phone", "location") {
+		switch v := a.Asset.(type) {
+		case *org.Organization:
+			if details.Organization == "" {
+				details.Organization = v.Name
+			}
+		case *people.Person:
+			details.Persons = append(details.Persons, v.FullName)
+		case *contact.EmailAddress:
+			details.Emails = append(details.Emails, strings.ToLower(v.Address))
+		case *contact.Phone:
+			if v.E164 != "" {
+				details.Phones = append(details.Phones, v.E164)
+			} else {
+				details.Phones = append(details.Phones, v.Raw)
+			}
+		case *contact.Location:
+			details.Locations = append(details.Locations, v.Address)
+		}
+	}
+
+	sort.Strings(details.Persons)
+	sort.Strings(details.Emails)
+	sort.Strings(details.Phones)
+	sort.Strings(details.Locations)
+	return details
+}
+
+func parseRegistrationDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+
+	for _, layout := range registrationDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// registrantDifferences returns the registrant details that have more than one value across the domains.
+func registrantDifferences(records []*RegistrationInfo) []*RegistrantDifference {
+	fields := []struct {
+		name   string
+		values func(c *ContactDetails) []string
+	}{
+		{"organization", func(c *ContactDetails) []string { return []string{c.Organization} }},
+		{"email", func(c *ContactDetails) []string { return c.Emails }},
+		{"phone", func(c *ContactDetails) []string { return c.Phones }},
+	}
+
+	diffs := []*RegistrantDifference{}
+	for _, f := range fields {
+		values := make(map[string][]string)
+
+		for _, rec := range records {
+			if rec.Type != RegistrationDomain || rec.Registrant == nil {
+				continue
+			}
+
+			for _, v := range f.values(rec.Registrant) {
+				if v != "" {
+					values[v] = append(values[v], rec.Name)
+				}
+			}
+		}
+
+		for v, list := range values {
+			values[v] = registrationDomains(list)
+		}
+		if len(values) > 1 {
+			diffs = append(diffs, &RegistrantDifference{Field: f.name, Values: values})
+		}
+	}
+	return diffs
+}
+
+// FprintRegistrations writes each registration record followed by the registrant details that differ.
+func FprintRegistrations(out io.Writer, report *RegistrationReport) {
+	if len(report.Records) == 0 {
+		fmt.Fprintln(out, blue("No registration records were found for the scope"))
+		return
+	}
+
+	for _, rec := range report.Records {
+		fmt.Fprintf(out, "%s %s\n", green(rec.Name), blue("("+rec.Type+")"))
+
+		fprintField(out, "Handle", rec.Handle)
+		fprintField(out, "Registrar", rec.Registrar)
+		fprintField(out, "Created", formatRegistrationDate(rec.Created))
+		fprintField(out, "Updated", formatRegistrationDate(rec.Updated))
+		fprintField(out, "Expires", formatRegistrationDate(rec.Expires))
+		fprintField(out, "NS", strings.Join(rec.NameServers, ", "))
+		if c := rec.Registrant; c != nil {
+			fprintField(out, "Org", c.Organization)
+			fprintField(out, "Emails", strings.Join(c.Emails, ", "))
+			fprintField(out, "Phones", strings.Join(c.Phones, ", "))
+		}
+
+		if rec.Expired {
+			fmt.Fprintf(out, "\t%s %s\n", blue(fmt.Sprintf("%-9s", "Flags")), r.Sprint("expired"))
+		} else if rec.ExpiringSoon {
+			fmt.Fprintf(out, "\t%s %s\n", blue(fmt.Sprintf("%-9s", "Flags")), r.Sprint("expiring soon"))
+		}
+	}
+
+	if len(report.Differences) == 0 {
+		return
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, blue("Registrant details that differ across the domains:"))
+	for _, d := range report.Differences {
+		fmt.Fprintf(out, "%s\n", yellow(d.Field))
+
+		values := make([]string, 0, len(d.Values))
+		for v := range d.Values {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+
+		for _, v := range values {
+			fmt.Fprintf(out, "\t%s %s\n", r.Sprint(v), green(strings.Join(d.Values[v], ", ")))
+		}
+	}
+}
+
+func formatRegistrationDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+// registrationRecords returns the registration records as CSV records, starting with the header.
+func registrationRecords(report *RegistrationReport) [][]string {
+	records := [][]string{{
+		"type", "name", "handle", "registrar", "created", "updated", "expires", "name_servers",
+		"registrant_organization", "registrant_emails", "registrant_phones", "expired", "expiring_soon",
+	}}
+
+	for _, rec := range report.Records {
+		c := rec.Registrant
+		if c == nil {
+			c = &ContactDetails{}
+		}
+
+		records = append(records, []string{
+			rec.Type,
+			rec.Name,
+			rec.Handle,
+			rec.Registrar,
+			formatRegistrationDate(rec.Created),
+			formatRegistrationDate(rec.Updated),
+			formatRegistrationDate(rec.Expires),
+			strings.Join(rec.NameServers, " "),
+			c.Organization,
+			strings.Join(c.Emails, " "),
+			strings.Join(c.Phones, " "),
+			strconv.FormatBool(rec.Expired),
+			strconv.FormatBool(rec.ExpiringSoon),
+		})
+	}
+	return records
+}
+
+// registrationDomains returns the sorted set of domains in the values, used to keep the output stable.
+func registrationDomains(list []string) []string {
+	set := stringset.New(list...)
+	defer set.Close()
+
+	domains := set.Slice()
+	sort.Strings(domains)
+	return domains
+}
@@ -0,0 +1,155 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/open-asset-model/contact"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/org"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrationInventory(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// A name hosted by the target, an alias of a cloud address, and an address outside the scope
+	_, err := g.UpsertA(ctx, "www.whoistest.domain", "93.184.216.140")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(ctx, "api.whoistest.domain", "edge.whoistest-cloud.net")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "edge.whoistest-cloud.net", "93.184.218.5")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "www.whoistest-other.com", "93.184.217.9")
+	assert.Nil(t, err)
+
+	root, err := g.DB.Create(nil, "", &domain.FQDN{Name: "whoistest.domain"})
+	assert.Nil(t, err)
+	for _, sub := range []string{"www", "api"} {
+		assets, err := g.DB.FindByContent(&domain.FQDN{Name: sub + ".whoistest.domain"}, time.Time{})
+		if assert.Nil(t, err) && assert.NotEmpty(t, assets) {
+			_, err = g.DB.Link(root, "node", assets[0])
+			assert.Nil(t, err)
+		}
+	}
+
+	contactOrg := func(record *types.Asset, rel, name string) *types.Asset {
+		cr, err := g.DB.Create(record, rel, &contact.ContactRecord{DiscoveredAt: "https://rdap.example/" + name})
+		assert.Nil(t, err)
+		_, err = g.DB.Create(cr, "organization", &org.Organization{Name: name})
+		assert.Nil(t, err)
+		return cr
+	}
+
+	// Domain registrations with different registrant organizations
+	dr, err := g.DB.Create(nil, "", &oamreg.DomainRecord{Domain: "whoistest.domain", ID: "D1-WHOISTEST",
+		ExpirationDate: now.Add(10 * 24 * time.Hour).Format(time.RFC3339)})
+	assert.Nil(t, err)
+	contactOrg(dr, "registrar_contact", "Whoistest Registrar")
+	cr := contactOrg(dr, "registrant_contact", "Whoistest Corp")
+	_, err = g.DB.Create(cr, "email", &contact.EmailAddress{Address: "Hostmaster@whoistest.domain"})
+	assert.Nil(t, err)
+	_, err = g.DB.Create(dr, "name_server", &domain.FQDN{Name: "NS1.whoistest.domain"})
+	assert.Nil(t, err)
+
+	dr, err = g.DB.Create(nil, "", &oamreg.DomainRecord{Domain: "whoistest.net", ExpirationDate: "2001-02-03"})
+	assert.Nil(t, err)
+	contactOrg(dr, "registrant_contact", "Whoistest Inc")
+
+	// Netblocks containing the in-scope addresses and the other address, announced by one AS
+	as, err := g.DB.Create(nil, "", &network.AutonomousSystem{Number: 64990})
+	assert.Nil(t, err)
+	_, err = g.DB.Create(as, "registration", &oamreg.AutnumRecord{Number: 64990, Handle: "AS64990", Name: "WHOISTEST-AS"})
+	assert.Nil(t, err)
+	for cidr, addr := range map[string]string{
+		"93.184.216.0/24": "93.184.216.140",
+		"93.184.217.0/24": "93.184.217.9",
+		"93.184.218.0/24": "93.184.218.5",
+	} {
+		prefix := netip.MustParsePrefix(cidr)
+
+		nb, err := g.DB.Create(nil, "", &network.Netblock{CIDR: prefix, Type: "IPv4"})
+		assert.Nil(t, err)
+		_, err = g.DB.Link(as, "announces", nb)
+		assert.Nil(t, err)
+		ips, err := g.DB.FindByContent(&network.IPAddress{Address: netip.MustParseAddr(addr), Type: "IPv4"}, time.Time{})
+		if assert.Nil(t, err) && assert.NotEmpty(t, ips) {
+			_, err = g.DB.Link(nb, "contains", ips[0])
+			assert.Nil(t, err)
+		}
+
+		rec, err := g.DB.Create(nb, "registration", &oamreg.IPNetRecord{CIDR: prefix, Handle: "NET-" + addr})
+		assert.Nil(t, err)
+		contactOrg(rec, "registrant", "Whoistest Hosting "+cidr)
+	}
+
+	report := RegistrationInventory(g, []string{"whoistest.domain", "whoistest.net"}, time.Time{}, now, 30*24*time.Hour)
+
+	var names []string
+	for _, rec := range report.Records {
+		names = append(names, rec.Type+" "+rec.Name)
+	}
+	assert.Equal(t, []string{
+		"domain whoistest.domain",
+		"domain whoistest.net",
+		"netblock 93.184.216.0/24",
+		"netblock 93.184.218.0/24",
+		"asn 64990",
+	}, names)
+
+	if assert.Len(t, report.Records, 5) {
+		rec := report.Records[0]
+		assert.Equal(t, "D1-WHOISTEST", rec.Handle)
+		assert.Equal(t, "Whoistest Registrar", rec.Registrar)
+		assert.Equal(t, []string{"ns1.whoistest.domain"}, rec.NameServers)
+		assert.Equal(t, "Whoistest Corp", rec.Registrant.Organization)
+		assert.Equal(t, []string{"hostmaster@whoistest.domain"}, rec.Registrant.Emails)
+		assert.True(t, rec.ExpiringSoon)
+		assert.False(t, rec.Expired)
+
+		rec = report.Records[1]
+		assert.True(t, rec.Expired)
+		assert.Equal(t, time.Date(2001, time.February, 3, 0, 0, 0, 0, time.UTC), rec.Expires)
+
+		assert.Equal(t, "Whoistest Hosting 93.184.218.0/24", report.Records[3].Registrant.Organization)
+
+		// The AS name stands in for the missing registrant
+		assert.Equal(t, "AS64990", report.Records[4].Handle)
+		assert.Equal(t, "WHOISTEST-AS", report.Records[4].Registrant.Organization)
+	}
+
+	if assert.Len(t, report.Differences, 1) {
+		d := report.Differences[0]
+		assert.Equal(t, "organization", d.Field)
+		assert.Equal(t, map[string][]string{
+			"Whoistest Corp": {"whoistest.domain"},
+			"Whoistest Inc":  {"whoistest.net"},
+		}, d.Values)
+	}
+
+	empty := RegistrationInventory(g, []string{"whoistest-missing.domain"}, time.Time{}, now, 0)
+	assert.Empty(t, empty.Records)
+	assert.Empty(t, empty.Differences)
+}
+
+func TestParseRegistrationDate(t *testing.T) {
+	expected := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)
+
+	for _, s := range []string{"2024-03-04T00:00:00Z", " 2024-03-04 00:00:00 ", "2024-03-04"} {
+		assert.True(t, expected.Equal(parseRegistrationDate(s)), s)
+	}
+	assert.True(t, parseRegistrationDate("04/03/2024").IsZero())
+	assert.True(t, parseRegistrationDate("").IsZero())
+}
@@ -0,0 +1,234 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+// oam_path: Explain which chain of relations connects an asset to the scope
+//
+//	+----------------------------------------------------------------------------+
+//	| ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░  OWASP Amass  ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░ |
+//	+----------------------------------------------------------------------------+
+//	|      .+++:.            :                             .+++.                 |
+//	|    +W@@@@@@8        &+W@#               o8W8:      +W@@@@@@#.   oW@@@W#+   |
+//	|   &@#+   .o@##.    .@@@o@W.o@@o       :@@#&W8o    .@#:  .:oW+  .@#+++&#&   |
+//	|  +@&        &@&     #@8 +@W@&8@+     :@W.   +@8   +@:          .@8         |
+//	|  8@          @@     8@o  8@8  WW    .@W      W@+  .@W.          o@#:       |
+//	|  WW          &@o    &@:  o@+  o@+   #@.      8@o   +W@#+.        +W@8:     |
+//	|  #@          :@W    &@+  &@+   @8  :@o       o@o     oW@@W+        oW@8    |
+//	|  o@+          @@&   &@+  &@+   #@  &@.      .W@W       .+#@&         o@W.  |
+//	|   WW         +@W@8. &@+  :&    o@+ #@      :@W&@&         &@:  ..     :@o  |
+//	|   :@W:      o@# +Wo &@+        :W: +@W&o++o@W. &@&  8@#o+&@W.  #@:    o@+  |
+//	|    :W@@WWWW@@8       +              :&W@@@@&    &W  .o#@@W&.   :W@WWW@@&   |
+//	|      +o&&&&+.                                                    +oooo.    |
+//	+----------------------------------------------------------------------------+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/caffix/stringset"
+	"github.com/fatih/color"
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/config/config"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/viz"
+)
+
+const (
+	timeFormat = "01/02 15:04:05 2006 MST"
+	usageMsg   = "-asset Type:key [options] -d domain"
+)
+
+var (
+	// Colors used to ease the reading of program output
+	g      = color.New(color.FgHiGreen)
+	r      = color.New(color.FgHiRed)
+	yellow = color.New(color.FgHiYellow).SprintFunc()
+	green  = color.New(color.FgHiGreen).SprintFunc()
+	blue   = color.New(color.FgHiBlue).SprintFunc()
+)
+
+type pathArgs struct {
+	Domains *stringset.Set
+	Asset   string
+	MaxHops int
+	Since   string
+	Options struct {
+		NoColor bool
+		Silent  bool
+	}
+	Filepaths struct {
+		ConfigFile string
+		Directory  string
+		Domains    string
+	}
+}
+
+func main() {
+	var args pathArgs
+	var help1, help2 bool
+	pathCommand := flag.NewFlagSet("path", flag.ContinueOnError)
+
+	args.Domains = stringset.New()
+	defer args.Domains.Close()
+
+	pathBuf := new(bytes.Buffer)
+	pathCommand.SetOutput(pathBuf)
+
+	pathCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	pathCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	pathCommand.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
+	pathCommand.StringVar(&args.Asset, "asset", "", "Asset to explain (format: Type:key, e.g. IPAddress:1.2.3.4)")
+	pathCommand.IntVar(&args.MaxHops, "hops", 6, "Maximum number of relations traversed from the asset")
+	pathCommand.StringVar(&args.Since, "since", "", "Include only assets validated after (format: "+timeFormat+")")
+	pathCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	pathCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	pathCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file")
+	pathCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
+	pathCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing registered domain names")
+
+	var usage = func() {
+		g.Fprintf(color.Error, "Usage: %s %s\n\n", path.Base(os.Args[0]), usageMsg)
+		pathCommand.PrintDefaults()
+		g.Fprintln(color.Error, pathBuf.String())
+	}
+
+	if len(os.Args) < 2 {
+		usage()
+		return
+	}
+	if err := pathCommand.Parse(os.Args[1:]); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 {
+		usage()
+		return
+	}
+	if args.Options.NoColor {
+		color.NoColor = true
+	}
+	if args.Options.Silent {
+		color.Output = io.Discard
+		color.Error = io.Discard
+	}
+	if args.Filepaths.Domains != "" {
+		list, err := config.GetListFromFile(args.Filepaths.Domains)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to parse the domain names file: %v\n", err)
+			os.Exit(1)
+		}
+		args.Domains.InsertMany(list...)
+	}
+	if args.Domains.Len() == 0 {
+		r.Fprintln(color.Error, "No root domain names were provided")
+		os.Exit(1)
+	}
+
+	asset, err := viz.ParseAsset(args.Asset)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to parse the asset: %v\n", err)
+		os.Exit(1)
+	}
+
+	var start time.Time
+	if args.Since != "" {
+		start, err = time.Parse(timeFormat, args.Since)
+		if err != nil {
+			r.Fprintf(color.Error, "%s is not in the correct format: %s\n", args.Since, timeFormat)
+			os.Exit(1)
+		}
+	}
+
+	cfg := config.NewConfig()
+	// Check if a configuration file was provided, and if so, load the settings
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err == nil {
+		if args.Filepaths.Directory == "" {
+			args.Filepaths.Directory = cfg.Dir
+		}
+		if args.Domains.Len() == 0 {
+			args.Domains.InsertMany(cfg.Domains()...)
+		}
+	} else if args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	// Connect with the graph database containing the enumeration data
+	db := openGraphDatabase(args.Filepaths.Directory, cfg)
+	if db == nil {
+		r.Fprintln(color.Error, "Failed to connect with the database")
+		os.Exit(1)
+	}
+
+	paths, err := viz.ScopePaths(asset, args.Domains.Slice(), args.MaxHops, start, db)
+	if err != nil {
+		r.Fprintf(color.Error, "%s %s: %v\n", asset.AssetType(), asset.Key(), err)
+		os.Exit(1)
+	}
+
+	for i, p := range paths {
+		printScopePath(i+1, p)
+	}
+}
+
+func printScopePath(num int, p viz.ScopePath) {
+	fmt.Fprintf(color.Output, "%s %s\n", blue(fmt.Sprintf("Path %d:", num)), yellow(fmt.Sprintf("%d hop(s)", len(p))))
+	if len(p) == 0 {
+		fmt.Fprintf(color.Output, "  %s\n\n", green("The asset is within the scope"))
+		return
+	}
+
+	fmt.Fprintf(color.Output, "  %s\n", green(assetString(p[0].From)))
+	for _, hop := range p {
+		arrow := "<--" + hop.Relation + "--"
+		if hop.Forward {
+			arrow = "--" + hop.Relation + "-->"
+		}
+
+		fmt.Fprintf(color.Output, "    %s %s %s\n", blue(arrow), green(assetString(hop.To)),
+			yellow("[relation last seen: "+formatTime(hop.LastSeen)+", asset first seen: "+
+				formatTime(hop.To.CreatedAt)+", last seen: "+formatTime(hop.To.LastSeen)+"]"))
+	}
+	fmt.Fprintln(color.Output)
+}
+
+func assetString(a *types.Asset) string {
+	return string(a.Asset.AssetType()) + ": " + a.Asset.Key()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.Format(timeFormat)
+}
+
+func openGraphDatabase(dir string, cfg *config.Config) *graph.Graph {
+	// Add the local database settings to the configuration
+	cfg.GraphDBs = append(cfg.GraphDBs, cfg.LocalDatabaseSettings(cfg.GraphDBs))
+
+	for _, db := range cfg.GraphDBs {
+		if db.Primary {
+			var g *graph.Graph
+
+			if db.System == "local" {
+				g = graph.NewGraph(db.System, filepath.Join(config.OutputDirectory(cfg.Dir), "amass.sqlite"), db.Options)
+			} else {
+				connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s", db.Host, db.Port, db.Username, db.Password, db.DBName)
+				g = graph.NewGraph(db.System, connStr, db.Options)
+			}
+
+			if g != nil {
+				return g
+			}
+			break
+		}
+	}
+	return nil
+}
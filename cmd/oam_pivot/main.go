@@ -0,0 +1,280 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+// oam_pivot: Answer reverse questions about which assets share infrastructure
+//
+//	+----------------------------------------------------------------------------+
+//	| ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░  OWASP Amass  ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░ |
+//	+----------------------------------------------------------------------------+
+//	|      .+++:.            :                             .+++.                 |
+//	|    +W@@@@@@8        &+W@#               o8W8:      +W@@@@@@#.   oW@@@W#+   |
+//	|   &@#+   .o@##.    .@@@o@W.o@@o       :@@#&W8o    .@#:  .:oW+  .@#+++&#&   |
+//	|  +@&        &@&     #@8 +@W@&8@+     :@W.   +@8   +@:          .@8         |
+//	|  8@          @@     8@o  8@8  WW    .@W      W@+  .@W.          o@#:       |
+//	|  WW          &@o    &@:  o@+  o@+   #@.      8@o   +W@#+.        +W@8:     |
+//	|  #@          :@W    &@+  &@+   @8  :@o       o@o     oW@@W+        oW@8    |
+//	|  o@+          @@&   &@+  &@+   #@  &@.      .W@W       .+#@&         o@W.  |
+//	|   WW         +@W@8. &@+  :&    o@+ #@      :@W&@&         &@:  ..     :@o  |
+//	|   :@W:      o@# +Wo &@+        :W: +@W&o++o@W. &@&  8@#o+&@W.  #@:    o@+  |
+//	|    :W@@WWWW@@8       +              :&W@@@@&    &W  .o#@@W&.   :W@WWW@@&   |
+//	|      +o&&&&+.                                                    +oooo.    |
+//	+----------------------------------------------------------------------------+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/owasp-amass/config/config"
+	"github.com/owasp-amass/engine/graph"
+)
+
+const (
+	timeFormat = "01/02 15:04:05 2006 MST"
+	usageMsg   = "-addr|-asn|-ns|-mx|-cert|-fingerprint value [options]"
+)
+
+var (
+	// Colors used to ease the reading of program output
+	g      = color.New(color.FgHiGreen)
+	r      = color.New(color.FgHiRed)
+	yellow = color.New(color.FgHiYellow).SprintFunc()
+	green  = color.New(color.FgHiGreen).SprintFunc()
+	blue   = color.New(color.FgHiBlue).SprintFunc()
+)
+
+type pivotArgs struct {
+	Since   string
+	Queries struct {
+		Addr        string
+		ASN         string
+		NS          string
+		MX          string
+		Cert        string
+		Fingerprint string
+	}
+	Options struct {
+		NoColor bool
+		Silent  bool
+	}
+	Filepaths struct {
+		ConfigFile string
+		Directory  string
+		JSONOutput string
+	}
+}
+
+func main() {
+	var args pivotArgs
+	var help1, help2 bool
+	pivotCommand := flag.NewFlagSet("pivot", flag.ContinueOnError)
+
+	pivotBuf := new(bytes.Buffer)
+	pivotCommand.SetOutput(pivotBuf)
+
+	pivotCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	pivotCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	pivotCommand.StringVar(&args.Queries.Addr, "addr", "", "Find the names resolving into an IP address or CIDR")
+	pivotCommand.StringVar(&args.Queries.ASN, "asn", "", "Find the names resolving into the netblocks announced by an ASN")
+	pivotCommand.StringVar(&args.Queries.NS, "ns", "", "Find the names sharing the provided NS record target")
+	pivotCommand.StringVar(&args.Queries.MX, "mx", "", "Find the names sharing the provided MX record target")
+	pivotCommand.StringVar(&args.Queries.Cert, "cert", "", "Find the hosts presenting the TLS certificate serial number")
+	pivotCommand.StringVar(&args.Queries.Fingerprint, "fingerprint", "", "Find the hosts presenting the service fingerprint")
+	pivotCommand.StringVar(&args.Since, "since", "", "Include only assets validated after (format: "+timeFormat+")")
+	pivotCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	pivotCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	pivotCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file")
+	pivotCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
+	pivotCommand.StringVar(&args.Filepaths.JSONOutput, "json", "", "Path to the JSON output file")
+
+	var usage = func() {
+		g.Fprintf(color.Error, "Usage: %s %s\n\n", path.Base(os.Args[0]), usageMsg)
+		pivotCommand.PrintDefaults()
+		g.Fprintln(color.Error, pivotBuf.String())
+	}
+
+	if len(os.Args) < 2 {
+		usage()
+		return
+	}
+	if err := pivotCommand.Parse(os.Args[1:]); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 {
+		usage()
+		return
+	}
+	if args.Options.NoColor {
+		color.NoColor = true
+	}
+	if args.Options.Silent {
+		color.Output = io.Discard
+		color.Error = io.Discard
+	}
+
+	q := args.Queries
+	if q.Addr == "" && q.ASN == "" && q.NS == "" && q.MX == "" && q.Cert == "" && q.Fingerprint == "" {
+		r.Fprintln(color.Error, "At least one pivot query must be provided")
+		os.Exit(1)
+	}
+
+	var err error
+	var start time.Time
+	if args.Since != "" {
+		start, err = time.Parse(timeFormat, args.Since)
+		if err != nil {
+			r.Fprintf(color.Error, "%s is not in the correct format: %s\n", args.Since, timeFormat)
+			os.Exit(1)
+		}
+	}
+
+	cfg := config.NewConfig()
+	// Check if a configuration file was provided, and if so, load the settings
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err == nil {
+		if args.Filepaths.Directory == "" {
+			args.Filepaths.Directory = cfg.Dir
+		}
+	} else if args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	// Connect with the graph database containing the enumeration data
+	db := openGraphDatabase(args.Filepaths.Directory, cfg)
+	if db == nil {
+		r.Fprintln(color.Error, "Failed to connect with the database")
+		os.Exit(1)
+	}
+
+	results, failed := runQueries(&args, start, db)
+	for _, res := range results {
+		printPivotResult(res)
+	}
+
+	if args.Filepaths.JSONOutput != "" {
+		if err := writeJSONFile(args.Filepaths.JSONOutput, results); err != nil {
+			r.Fprintf(color.Error, "Failed to write the JSON output file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runQueries performs the provided pivot queries and reports whether any of them failed.
+func runQueries(args *pivotArgs, since time.Time, db *graph.Graph) (results []*PivotResult, failed bool) {
+	results = []*PivotResult{}
+	if !since.IsZero() {
+		since = since.UTC()
+	}
+
+	collect := func(res *PivotResult, err error, query string) {
+		if err != nil {
+			r.Fprintf(color.Error, "%s: %v\n", query, err)
+			failed = true
+			return
+		}
+		results = append(results, res)
+	}
+
+	q := args.Queries
+	if q.Addr != "" {
+		res, err := NamesByAddr(db, q.Addr, since)
+		collect(res, err, q.Addr)
+	}
+	if q.ASN != "" {
+		asn, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(q.ASN), "AS"))
+		if err != nil {
+			r.Fprintf(color.Error, "%s is not a valid ASN\n", q.ASN)
+			failed = true
+		} else {
+			res, err := NamesByASN(db, asn, since)
+			collect(res, err, q.ASN)
+		}
+	}
+	if q.NS != "" {
+		res, err := NamesByTarget(db, "ns_record", q.NS, since)
+		collect(res, err, q.NS)
+	}
+	if q.MX != "" {
+		res, err := NamesByTarget(db, "mx_record", q.MX, since)
+		collect(res, err, q.MX)
+	}
+	if q.Cert != "" {
+		res, err := HostsByCertificate(db, q.Cert, since)
+		collect(res, err, q.Cert)
+	}
+	if q.Fingerprint != "" {
+		res, err := HostsByFingerprint(db, q.Fingerprint, since)
+		collect(res, err, q.Fingerprint)
+	}
+	return results, failed
+}
+
+func printPivotResult(res *PivotResult) {
+	fmt.Fprintf(color.Output, "%s %s %s\n", blue(res.Type+":"), yellow(res.Query),
+		green(fmt.Sprintf("(%d matches)", len(res.Matches))))
+
+	for _, m := range res.Matches {
+		var via string
+		if len(m.Via) > 0 {
+			via = " " + strings.Join(m.Via, ",")
+		}
+		fmt.Fprintf(color.Output, "%s%s\n", green(m.Name), yellow(via))
+	}
+	fmt.Fprintln(color.Output)
+}
+
+func writeJSONFile(path string, results []*PivotResult) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Sync()
+		_ = f.Close()
+	}()
+
+	_ = f.Truncate(0)
+	_, _ = f.Seek(0, 0)
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+func openGraphDatabase(dir string, cfg *config.Config) *graph.Graph {
+	// Add the local database settings to the configuration
+	cfg.GraphDBs = append(cfg.GraphDBs, cfg.LocalDatabaseSettings(cfg.GraphDBs))
+
+	for _, db := range cfg.GraphDBs {
+		if db.Primary {
+			var g *graph.Graph
+
+			if db.System == "local" {
+				g = graph.NewGraph(db.System, filepath.Join(config.OutputDirectory(cfg.Dir), "amass.sqlite"), db.Options)
+			} else {
+				connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s", db.Host, db.Port, db.Username, db.Password, db.DBName)
+				g = graph.NewGraph(db.System, connStr, db.Options)
+			}
+
+			if g != nil {
+				return g
+			}
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caffix/stringset"
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	"github.com/owasp-amass/oam-tools/viz"
+	oam "github.com/owasp-amass/open-asset-model"
+	oamcert "github.com/owasp-amass/open-asset-model/certificate"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/fingerprint"
+	"github.com/owasp-amass/open-asset-model/network"
+)
+
+// The relations followed backwards from a certificate or fingerprint to the hosts presenting it
+var presentingRels = []string{"certificate", "fingerprint", "service", "port"}
+
+// PivotResult contains the answer to a single reverse lookup performed on the graph.
+type PivotResult struct {
+	Query   string        `json:"query"`
+	Type    string        `json:"type"`
+	Matches []*PivotMatch `json:"matches"`
+}
+
+// PivotMatch is an asset found by the reverse lookup along with the assets that linked it to the query.
+type PivotMatch struct {
+	Name string   `json:"name"`
+	Via  []string `json:"via,omitempty"`
+}
+
+type matchSet map[string]*stringset.Set
+
+func (m matchSet) add(name string, via ...string) {
+	if _, found := m[name]; !found {
+		m[name] = stringset.New()
+	}
+	m[name].InsertMany(via...)
+}
+
+func (m matchSet) result(query, qtype string) *PivotResult {
+	res := &PivotResult{
+		Query:   query,
+		Type:    qtype,
+		Matches: []*PivotMatch{},
+	}
+
+	for name, via := range m {
+		vlist := via.Slice()
+		via.Close()
+
+		sort.Strings(vlist)
+		res.Matches = append(res.Matches, &PivotMatch{Name: name, Via: vlist})
+	}
+
+	sort.Slice(res.Matches, func(i, j int) bool {
+		return res.Matches[i].Name < res.Matches[j].Name
+	})
+	return res
+}
+
+// NamesByAddr returns the FQDNs resolving into the provided IP address or CIDR.
+func NamesByAddr(g *graph.Graph, addr string, since time.Time) (*PivotResult, error) {
+	matches := make(matchSet)
+
+	if !strings.Contains(addr, "/") {
+		ip, err := netip.ParseAddr(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		if assets, err := g.DB.FindByContent(&network.IPAddress{Address: ip}, since); err == nil {
+			for _, a := range assets {
+				for _, name := range namesResolvingTo(g, a, since) {
+					matches.add(name, ip.String())
+				}
+			}
+		}
+		return matches.result(addr, "address"), nil
+	}
+
+	prefix, err := netip.ParsePrefix(addr)
+	if err != nil {
+		return nil, err
+	}
+	prefix = prefix.Masked()
+
+	// Addresses without a known netblock are also within the prefix
+	if addrs, err := g.DB.FindByType(oam.IPAddress, since); err == nil {
+		for _, a := range addrs {
+			if ip, ok := a.Asset.(*network.IPAddress); ok && prefix.Contains(ip.Address) {
+				for _, name := range namesResolvingTo(g, a, since) {
+					matches.add(name, ip.Address.String())
+				}
+			}
+		}
+	}
+	return matches.result(addr, "address"), nil
+}
+
+// NamesByASN returns the FQDNs resolving into the netblocks announced by the provided autonomous system.
+func NamesByASN(g *graph.Graph, asn int, since time.Time) (*PivotResult, error) {
+	matches := make(matchSet)
+
+	var found bool
+	if assets, err := g.DB.FindByContent(&network.AutonomousSystem{Number: asn}, since); err == nil {
+		for _, as := range assets {
+			for _, nb := range requests.OutgoingAssets(g, as, since, "announces") {
+				if n, ok := nb.Asset.(*network.Netblock); ok {
+					found = true
+					namesInNetblock(g, nb, n.CIDR.Masked(), since, matches)
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, errors.New("no netblocks are announced by the autonomous system")
+	}
+	return matches.result("AS"+strconv.Itoa(asn), "asn"), nil
+}
+
+// NamesByTarget returns the FQDNs having a record of the provided type that points to the target name.
+func NamesByTarget(g *graph.Graph, rtype, target string, since time.Time) (*PivotResult, error) {
+	target = strings.ToLower(strings.TrimSpace(target))
+
+	assets, err := g.DB.FindByContent(&domain.FQDN{Name: target}, since)
+	if err != nil || len(assets) == 0 {
+		return nil, errors.New("the target name was not found in the graph")
+	}
+
+	matches := make(matchSet)
+	for _, a := range assets {
+		for _, from := range requests.IncomingAssets(g, a, since, rtype) {
+			if n, ok := from.Asset.(*domain.FQDN); ok {
+				matches.add(n.Name, target)
+			}
+		}
+	}
+	return matches.result(target, rtype), nil
+}
+
+// HostsByCertificate returns the hosts presenting the TLS certificate with the provided serial number.
+func HostsByCertificate(g *graph.Graph, serial string, since time.Time) (*PivotResult, error) {
+	assets, err := g.DB.FindByContent(&oamcert.TLSCertificate{SerialNumber: serial}, since)
+	if err != nil || len(assets) == 0 {
+		return nil, errors.New("the certificate was not found in the graph")
+	}
+
+	matches := make(matchSet)
+	for _, a := range assets {
+		presentingHosts(g, a, since, matches)
+	}
+	return matches.result(serial, "certificate"), nil
+}
+
+// HostsByFingerprint returns the hosts presenting a service with the provided fingerprint value.
+func HostsByFingerprint(g *graph.Graph, value string, since time.Time) (*PivotResult, error) {
+	assets, err := g.DB.FindByContent(&fingerprint.Fingerprint{Value: value}, since)
+	if err != nil || len(assets) == 0 {
+		return nil, errors.New("the fingerprint was not found in the graph")
+	}
+
+	matches := make(matchSet)
+	for _, a := range assets {
+		presentingHosts(g, a, since, matches)
+	}
+	return matches.result(value, "fingerprint"), nil
+}
+
+// namesInNetblock follows the contains relations of the netblock to the addresses within the prefix.
+func namesInNetblock(g *graph.Graph, nb *types.Asset, prefix netip.Prefix, since time.Time, matches matchSet) {
+	for _, a := range requests.OutgoingAssets(g, nb, since, "contains") {
+		ip, ok := a.Asset.(*network.IPAddress)
+		if !ok || !prefix.Contains(ip.Address) {
+			continue
+		}
+
+		addr := ip.Address.String()
+		for _, name := range namesResolvingTo(g, a, since) {
+			matches.add(name, addr)
+		}
+	}
+}
+
+// namesResolvingTo runs the address records and CNAME chains in reverse.
+func namesResolvingTo(g *graph.Graph, addr *types.Asset, since time.Time) []string {
+	names := stringset.New()
+	defer names.Close()
+
+	queue := requests.IncomingAssets(g, addr, since, "a_record", "aaaa_record")
+	seen := make(map[string]struct{})
+	for len(queue) > 0 {
+		a := queue[0]
+		queue = queue[1:]
+
+		if _, found := seen[a.ID]; found {
+			continue
+		}
+		seen[a.ID] = struct{}{}
+
+		if n, ok := a.Asset.(*domain.FQDN); ok {
+			names.Insert(n.Name)
+			queue = append(queue, requests.IncomingAssets(g, a, since, "cname_record")...)
+		}
+	}
+	return names.Slice()
+}
+
+// presentingHosts adds the network endpoints and socket addresses presenting the certificate or fingerprint.
+func presentingHosts(g *graph.Graph, asset *types.Asset, since time.Time, matches matchSet) {
+	for _, a := range viz.PresentingHosts(g, asset, since, presentingRels...) {
+		switch v := a.Asset.(type) {
+		case *domain.NetworkEndpoint:
+			matches.add(v.Name, v.Address)
+		case *network.SocketAddress:
+			matches.add(v.IPAddress.String(), v.Address.String())
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/graph"
+	oamcert "github.com/owasp-amass/open-asset-model/certificate"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/fingerprint"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPivots(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+
+	// Two hosts in one netblock, one of them behind an alias, and a host in another netblock
+	_, err := g.UpsertA(ctx, "www.pivottest.domain", "93.184.216.160")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(ctx, "shop.pivottest-brand.com", "www.pivottest.domain")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "mail.pivottest.domain", "93.184.216.161")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "www.pivottest-other.org", "93.184.217.10")
+	assert.Nil(t, err)
+	// An address resolved without any netblock data
+	_, err = g.UpsertA(ctx, "vpn.pivottest.domain", "93.184.219.20")
+	assert.Nil(t, err)
+
+	// A netblock announced by an AS, and one without an announcement
+	as, err := g.DB.Create(nil, "", &network.AutonomousSystem{Number: 64980})
+	assert.Nil(t, err)
+	for cidr, addrs := range map[string][]string{
+		"93.184.216.0/24": {"93.184.216.160", "93.184.216.161"},
+		"93.184.217.0/24": {"93.184.217.10"},
+	} {
+		nb, err := g.DB.Create(nil, "", &network.Netblock{CIDR: netip.MustParsePrefix(cidr), Type: "IPv4"})
+		assert.Nil(t, err)
+		if cidr == "93.184.216.0/24" {
+			_, err = g.DB.Link(as, "announces", nb)
+			assert.Nil(t, err)
+		}
+
+		for _, addr := range addrs {
+			ips, err := g.DB.FindByContent(&network.IPAddress{Address: netip.MustParseAddr(addr), Type: "IPv4"}, time.Time{})
+			if assert.Nil(t, err) && assert.NotEmpty(t, ips) {
+				_, err = g.DB.Link(nb, "contains", ips[0])
+				assert.Nil(t, err)
+			}
+		}
+	}
+
+	// Names sharing a name server
+	for _, name := range []string{"pivottest.domain", "pivottest-brand.com"} {
+		_, err = g.UpsertNS(ctx, name, "ns1.pivottest-dns.net")
+		assert.Nil(t, err)
+	}
+
+	// A certificate and a fingerprint presented by an endpoint and a socket address
+	www, err := g.DB.FindByContent(&domain.FQDN{Name: "www.pivottest.domain"}, time.Time{})
+	if assert.Nil(t, err) && assert.NotEmpty(t, www) {
+		ep, err := g.DB.Create(www[0], "port", &domain.NetworkEndpoint{
+			Address: "www.pivottest.domain:443", Name: "www.pivottest.domain", Port: 443, Protocol: "https"})
+		assert.Nil(t, err)
+		svc, err := g.DB.Create(ep, "service", &service.Service{Identifier: "pivottest-web"})
+		assert.Nil(t, err)
+		_, err = g.DB.Create(svc, "certificate", &oamcert.TLSCertificate{SerialNumber: "pivottest01"})
+		assert.Nil(t, err)
+		_, err = g.DB.Create(svc, "fingerprint", &fingerprint.Fingerprint{Type: "jarm", Value: "pivottest"})
+		assert.Nil(t, err)
+	}
+	ip := netip.MustParseAddr("93.184.217.10")
+	sa, err := g.DB.Create(nil, "", &network.SocketAddress{
+		Address: netip.AddrPortFrom(ip, 8443), IPAddress: ip, Port: 8443, Protocol: "https"})
+	assert.Nil(t, err)
+	svc, err := g.DB.Create(sa, "service", &service.Service{Identifier: "pivottest-alt"})
+	assert.Nil(t, err)
+	_, err = g.DB.Create(svc, "fingerprint", &fingerprint.Fingerprint{Type: "jarm", Value: "pivottest"})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		pivot    func() (*PivotResult, error)
+		expected map[string][]string
+	}{
+		{
+			name:  "single address",
+			pivot: func() (*PivotResult, error) { return NamesByAddr(g, "93.184.216.160", time.Time{}) },
+			expected: map[string][]string{
+				"shop.pivottest-brand.com": {"93.184.216.160"},
+				"www.pivottest.domain":     {"93.184.216.160"},
+			},
+		},
+		{
+			name:  "prefix within a netblock",
+			pivot: func() (*PivotResult, error) { return NamesByAddr(g, "93.184.216.161/32", time.Time{}) },
+			expected: map[string][]string{
+				"mail.pivottest.domain": {"93.184.216.161"},
+			},
+		},
+		{
+			name:  "prefix covering netblocks",
+			pivot: func() (*PivotResult, error) { return NamesByAddr(g, "93.184.216.0/23", time.Time{}) },
+			expected: map[string][]string{
+				"mail.pivottest.domain":    {"93.184.216.161"},
+				"shop.pivottest-brand.com": {"93.184.216.160"},
+				"www.pivottest.domain":     {"93.184.216.160"},
+				"www.pivottest-other.org":  {"93.184.217.10"},
+			},
+		},
+		{
+			name:  "prefix without a netblock",
+			pivot: func() (*PivotResult, error) { return NamesByAddr(g, "93.184.219.0/24", time.Time{}) },
+			expected: map[string][]string{
+				"vpn.pivottest.domain": {"93.184.219.20"},
+			},
+		},
+		{
+			name:     "address not in the graph",
+			pivot:    func() (*PivotResult, error) { return NamesByAddr(g, "93.184.216.200", time.Time{}) },
+			expected: map[string][]string{},
+		},
+		{
+			name:  "announced netblocks",
+			pivot: func() (*PivotResult, error) { return NamesByASN(g, 64980, time.Time{}) },
+			expected: map[string][]string{
+				"mail.pivottest.domain":    {"93.184.216.161"},
+				"shop.pivottest-brand.com": {"93.184.216.160"},
+				"www.pivottest.domain":     {"93.184.216.160"},
+			},
+		},
+		{
+			name: "shared name server",
+			pivot: func() (*PivotResult, error) {
+				return NamesByTarget(g, "ns_record", "NS1.pivottest-dns.net", time.Time{})
+			},
+			expected: map[string][]string{
+				"pivottest-brand.com": {"ns1.pivottest-dns.net"},
+				"pivottest.domain":    {"ns1.pivottest-dns.net"},
+			},
+		},
+		{
+			name:  "certificate",
+			pivot: func() (*PivotResult, error) { return HostsByCertificate(g, "pivottest01", time.Time{}) },
+			expected: map[string][]string{
+				"www.pivottest.domain": {"www.pivottest.domain:443"},
+			},
+		},
+		{
+			name:  "fingerprint",
+			pivot: func() (*PivotResult, error) { return HostsByFingerprint(g, "pivottest", time.Time{}) },
+			expected: map[string][]string{
+				"93.184.217.10":        {"93.184.217.10:8443"},
+				"www.pivottest.domain": {"www.pivottest.domain:443"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		res, err := test.pivot()
+		if !assert.Nil(t, err, test.name) {
+			continue
+		}
+
+		matches := make(map[string][]string)
+		for _, m := range res.Matches {
+			matches[m.Name] = m.Via
+		}
+		assert.Equal(t, test.expected, matches, test.name)
+	}
+
+	_, err = NamesByAddr(g, "93.184.216.0/33", time.Time{})
+	assert.NotNil(t, err)
+	_, err = NamesByASN(g, 64981, time.Time{})
+	assert.NotNil(t, err)
+	_, err = HostsByCertificate(g, "pivottest-missing", time.Time{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,53 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/owasp-amass/oam-tools/viz"
+)
+
+// FprintDependencies writes the out-of-scope domains, organizations and autonomous systems,
+// each with the number of in-scope names relying on it.
+func FprintDependencies(out io.Writer, report *viz.DependencyReport, rd *redact.Redactor) {
+	fprintDependencySection(out, "Third-party domains:", report.Domains, rd, rd.Domain)
+	fmt.Fprintln(out)
+	fprintDependencySection(out, "Organizations:", report.Organizations, rd, rd.Organization)
+	fmt.Fprintln(out)
+	fprintDependencySection(out, "Autonomous systems:", report.ASNs, rd, func(name string) string {
+		asn, err := strconv.Atoi(name)
+		if err != nil {
+			return rd.Value(name)
+		}
+		return strconv.Itoa(rd.ASN(asn))
+	})
+}
+
+// fprintDependencySection writes the dependencies, replacing their names with the pseudonym
+// and leaving out the descriptions when a Redactor is provided.
+func fprintDependencySection(out io.Writer, title string, deps []*viz.Dependency, rd *redact.Redactor, pseudonym func(string) string) {
+	fmt.Fprintln(out, blue(title))
+	if len(deps) == 0 {
+		fmt.Fprintf(out, "\t%s\n", yellow("None found"))
+		return
+	}
+
+	for _, d := range deps {
+		name := d.Name
+		if rd != nil {
+			name = pseudonym(name)
+		} else if d.Description != "" {
+			name += " " + d.Description
+		}
+
+		fmt.Fprintf(out, "\t%s %s %s\n", yellow(fmt.Sprintf("%-6d", len(d.Names))),
+			green(name), blue("("+strings.Join(d.Relations, ",")+")"))
+	}
+}
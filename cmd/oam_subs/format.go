@@ -8,10 +8,14 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/owasp-amass/oam-tools/requests"
 )
 
 const (
@@ -33,15 +37,27 @@ var (
 	blue   = color.New(color.FgHiBlue).SprintFunc()
 )
 
+// Orderings supported by the ASN table summary.
+const (
+	SummarySortASN   = "asn"
+	SummarySortNames = "names"
+	SummarySortIPs   = "ips"
+)
+
 // ASNSummaryData stores information related to discovered ASs and netblocks.
 type ASNSummaryData struct {
-	Name      string
-	Netblocks map[string]int
+	Name           string
+	CC             string
+	Registry       string
+	AllocationDate time.Time
+	Names          map[string]struct{}
+	Addresses      map[string]struct{}
+	Netblocks      map[string]int
 }
 
 // DesiredAddrTypes removes undesired address types from the AddressInfo slice.
-func DesiredAddrTypes(addrs []AddressInfo, ipv4, ipv6 bool) []AddressInfo {
-	var kept []AddressInfo
+func DesiredAddrTypes(addrs []requests.AddressInfo, ipv4, ipv6 bool) []requests.AddressInfo {
+	var kept []requests.AddressInfo
 
 	for _, addr := range addrs {
 		if ipv4 && IsIPv4(addr.Address) {
@@ -65,7 +81,7 @@ func IsIPv6(ip net.IP) bool {
 }
 
 // UpdateSummaryData updates the summary maps using the provided requests.Output data.
-func UpdateSummaryData(output *Output, asns map[int]*ASNSummaryData) {
+func UpdateSummaryData(output *requests.Output, asns map[int]*ASNSummaryData) {
 	for _, addr := range output.Addresses {
 		if addr.CIDRStr == "" {
 			continue
@@ -75,22 +91,38 @@ func UpdateSummaryData(output *Output, asns map[int]*ASNSummaryData) {
 		if !found {
 			asns[addr.ASN] = &ASNSummaryData{
 				Name:      addr.Description,
+				Names:     make(map[string]struct{}),
+				Addresses: make(map[string]struct{}),
 				Netblocks: make(map[string]int),
 			}
 			data = asns[addr.ASN]
 		}
+		if data.CC == "" {
+			data.CC = addr.CC
+		}
+		if data.Registry == "" {
+			data.Registry = addr.Registry
+		}
+		if data.AllocationDate.IsZero() {
+			data.AllocationDate = addr.AllocationDate
+		}
+
+		data.Names[output.Name] = struct{}{}
+		data.Addresses[addr.Address.String()] = struct{}{}
 		// Increment how many IPs were in this netblock
 		data.Netblocks[addr.CIDRStr]++
 	}
 }
 
 // PrintEnumerationSummary outputs the summary information utilized by the command-line tools.
-func PrintEnumerationSummary(total int, asns map[int]*ASNSummaryData, demo bool) {
-	FprintEnumerationSummary(color.Error, total, asns, demo)
+func PrintEnumerationSummary(total int, asns map[int]*ASNSummaryData, sortBy string, rd *redact.Redactor) {
+	FprintEnumerationSummary(color.Error, total, asns, sortBy, rd)
 }
 
 // FprintEnumerationSummary outputs the summary information utilized by the command-line tools.
-func FprintEnumerationSummary(out io.Writer, total int, asns map[int]*ASNSummaryData, demo bool) {
+// The autonomous systems are ordered using one of the SummarySort constants, and the identifying
+// details are replaced with pseudonyms when a Redactor is provided.
+func FprintEnumerationSummary(out io.Writer, total int, asns map[int]*ASNSummaryData, sortBy string, rd *redact.Redactor) {
 	pad := func(num int, chr string) {
 		for i := 0; i < num; i++ {
 			b.Fprint(out, chr)
@@ -116,74 +148,157 @@ func FprintEnumerationSummary(out io.Writer, total int, asns map[int]*ASNSummary
 	pad(8, "----------")
 	fmt.Fprintln(out)
 	// Print the ASN and netblock information
-	for asn, data := range asns {
-		asnstr := strconv.Itoa(asn)
+	for _, asn := range sortedSummaryASNs(asns, sortBy) {
+		data := asns[asn]
+		asnstr := strconv.Itoa(rd.ASN(asn))
 		datastr := data.Name
-
-		if demo && asn > 0 {
-			asnstr = censorString(asnstr, 0, len(asnstr))
-			datastr = censorString(datastr, 0, len(datastr))
+		if asn > 0 {
+			datastr = rd.Organization(datastr)
 		}
-		fmt.Fprintf(out, "%s%s %s %s\n", blue("ASN: "), yellow(asnstr), green("-"), green(datastr))
-
-		for cidr, ips := range data.Netblocks {
-			countstr := strconv.Itoa(ips)
-			cidrstr := cidr
+		fmt.Fprintf(out, "%s%s %s %s%s %s\n", blue("ASN: "), yellow(asnstr), green("-"), green(datastr),
+			blue(registrationDetails(data)), yellow(fmt.Sprintf("(%d names, %d IPs)", len(data.Names), len(data.Addresses))))
 
-			if demo {
-				cidrstr = censorNetBlock(cidrstr)
-			}
+		for _, cidr := range sortedSummaryNetblocks(data.Netblocks) {
+			countstr := strconv.Itoa(data.Netblocks[cidr])
+			cidrstr := rd.Netblock(cidr)
 
 			countstr = fmt.Sprintf("\t%-4s", countstr)
 			cidrstr = fmt.Sprintf("\t%-18s", cidrstr)
 			fmt.Fprintf(out, "%s%s %s\n", yellow(cidrstr), yellow(countstr), blue("Subdomain Name(s)"))
 		}
 	}
+
+	pad(8, "----------")
+	fmt.Fprintln(out)
+	fprintSummaryTotals(out, "Country", asns, func(d *ASNSummaryData) string { return d.CC })
+	fprintSummaryTotals(out, "Registry", asns, func(d *ASNSummaryData) string { return d.Registry })
 }
 
-func censorString(input string, start, end int) string {
-	runes := []rune(input)
-	for i := start; i < end; i++ {
-		if runes[i] == '.' ||
-			runes[i] == '/' ||
-			runes[i] == '-' ||
-			runes[i] == ' ' {
-			continue
-		}
-		runes[i] = 'x'
+func registrationDetails(data *ASNSummaryData) string {
+	var details []string
+
+	if data.CC != "" {
+		details = append(details, data.CC)
+	}
+	if data.Registry != "" {
+		details = append(details, data.Registry)
+	}
+	if !data.AllocationDate.IsZero() {
+		details = append(details, data.AllocationDate.Format("2006-01-02"))
 	}
-	return string(runes)
+
+	if len(details) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(details, ", ") + "]"
+}
+
+type summaryTotal struct {
+	key   string
+	asns  int
+	names map[string]struct{}
+	addrs map[string]struct{}
 }
 
-func censorDomain(input string) string {
-	return censorString(input, strings.Index(input, "."), len(input))
+// fprintSummaryTotals outputs the number of ASNs, names and IPs grouped by the provided key.
+func fprintSummaryTotals(out io.Writer, label string, asns map[int]*ASNSummaryData, key func(*ASNSummaryData) string) {
+	totals := make(map[string]*summaryTotal)
+
+	for _, data := range asns {
+		k := key(data)
+		if k == "" {
+			k = "Unknown"
+		}
+
+		t, found := totals[k]
+		if !found {
+			t = &summaryTotal{
+				key:   k,
+				names: make(map[string]struct{}),
+				addrs: make(map[string]struct{}),
+			}
+			totals[k] = t
+		}
+
+		t.asns++
+		for name := range data.Names {
+			t.names[name] = struct{}{}
+		}
+		for addr := range data.Addresses {
+			t.addrs[addr] = struct{}{}
+		}
+	}
+
+	list := make([]*summaryTotal, 0, len(totals))
+	for _, t := range totals {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if len(list[i].names) != len(list[j].names) {
+			return len(list[i].names) > len(list[j].names)
+		}
+		return list[i].key < list[j].key
+	})
+
+	for _, t := range list {
+		fmt.Fprintf(out, "%s%s %s\n", blue(fmt.Sprintf("%-10s", label+":")), yellow(fmt.Sprintf("%-8s", t.key)),
+			green(fmt.Sprintf("%d ASNs, %d names, %d IPs", t.asns, len(t.names), len(t.addrs))))
+	}
 }
 
-func censorIP(input string) string {
-	return censorString(input, 0, strings.LastIndex(input, "."))
+// sortedSummaryASNs returns the ASNs ordered by the number of names, number of IPs or the ASN.
+// Counts are sorted in descending order and ties are broken by the ASN.
+func sortedSummaryASNs(asns map[int]*ASNSummaryData, sortBy string) []int {
+	keys := make([]int, 0, len(asns))
+	for asn := range asns {
+		keys = append(keys, asn)
+	}
+
+	count := func(asn int) int {
+		switch sortBy {
+		case SummarySortNames:
+			return len(asns[asn].Names)
+		case SummarySortIPs:
+			return len(asns[asn].Addresses)
+		}
+		return 0
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if ci, cj := count(keys[i]), count(keys[j]); ci != cj {
+			return ci > cj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
 }
 
-func censorNetBlock(input string) string {
-	return censorString(input, 0, strings.Index(input, "/"))
+// sortedSummaryNetblocks returns the CIDRs ordered by the number of names in descending order.
+func sortedSummaryNetblocks(netblocks map[string]int) []string {
+	keys := make([]string, 0, len(netblocks))
+	for cidr := range netblocks {
+		keys = append(keys, cidr)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if netblocks[keys[i]] != netblocks[keys[j]] {
+			return netblocks[keys[i]] > netblocks[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
 }
 
 // OutputLineParts returns the parts of a line to be printed for a requests.Output.
-func OutputLineParts(out *Output, addrs, demo bool) (name, ips string) {
+func OutputLineParts(out *requests.Output, addrs bool, rd *redact.Redactor) (name, ips string) {
 	if addrs {
 		for i, a := range out.Addresses {
 			if i != 0 {
 				ips += ","
 			}
-			if demo {
-				ips += censorIP(a.Address.String())
-			} else {
-				ips += a.Address.String()
-			}
+			ips += rd.IP(a.Address.String())
 		}
 	}
-	name = out.Name
-	if demo {
-		name = censorDomain(name)
-	}
+	name = rd.Domain(out.Name)
 	return
 }
@@ -0,0 +1,203 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/owasp-amass/oam-tools/redact"
+)
+
+// The number of labels and patterns shown in the label statistics report
+const topLabelsLimit = 25
+
+// Labels commonly used to separate the environments of an organization
+var environmentLabels = []string{
+	"dev", "develop", "development", "stg", "stage", "staging", "prod", "production", "preprod",
+	"qa", "uat", "test", "testing", "int", "sandbox", "sbx", "demo", "perf", "beta", "alpha",
+}
+
+var numericLabelRE = regexp.MustCompile(`^([a-z]+(?:[-_][a-z]+)*?)([-_]?)([0-9]+)$`)
+
+// LabelStats contains the statistics collected from the subdomain labels of the discovered names.
+type LabelStats struct {
+	Names    int
+	Labels   map[string]int
+	Depths   map[int]int
+	Patterns map[string]int
+}
+
+// AnalyzeLabels collects the label statistics for the names, ignoring the labels of the root domains.
+func AnalyzeLabels(names []string, domains []string) *LabelStats {
+	stats := &LabelStats{
+		Labels:   make(map[string]int),
+		Depths:   make(map[int]int),
+		Patterns: make(map[string]int),
+	}
+
+	for _, n := range names {
+		name := strings.ToLower(strings.TrimSuffix(n, "."))
+
+		root := rootDomain(name, domains)
+		if root == "" || name == root {
+			continue
+		}
+
+		labels := strings.Split(strings.TrimSuffix(name, "."+root), ".")
+		stats.Names++
+		stats.Depths[len(labels)]++
+
+		for _, label := range labels {
+			if label == "" {
+				continue
+			}
+
+			stats.Labels[label]++
+			for _, p := range labelPatterns(label) {
+				stats.Patterns[p]++
+			}
+		}
+	}
+	return stats
+}
+
+// labelPatterns returns the numeric and environment patterns found in the label,
+// such as 'dev##' for 'dev01' and 'stg-*' for 'stg-api'.
+func labelPatterns(label string) []string {
+	var patterns []string
+
+	if m := numericLabelRE.FindStringSubmatch(label); m != nil {
+		patterns = append(patterns, m[1]+m[2]+strings.Repeat("#", len(m[3])))
+	}
+
+	tokens := strings.FieldsFunc(label, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	if len(tokens) < 2 {
+		return patterns
+	}
+
+	sep := "-"
+	if strings.Contains(label, "_") && !strings.Contains(label, "-") {
+		sep = "_"
+	}
+
+	first := strings.TrimRight(tokens[0], "0123456789")
+	last := strings.TrimRight(tokens[len(tokens)-1], "0123456789")
+	if isEnvironmentLabel(first) {
+		patterns = append(patterns, first+sep+"*")
+	}
+	if isEnvironmentLabel(last) {
+		patterns = append(patterns, "*"+sep+last)
+	}
+	return patterns
+}
+
+func isEnvironmentLabel(label string) bool {
+	for _, env := range environmentLabels {
+		if label == env {
+			return true
+		}
+	}
+	return false
+}
+
+// Wordlist returns the deduplicated labels ranked by frequency, breaking ties lexically.
+func (s *LabelStats) Wordlist() []string {
+	return rankedKeys(s.Labels)
+}
+
+// FprintLabelStats writes the most frequent labels, the depth distribution and the label patterns.
+// The labels and the pattern prefixes are replaced with their pseudonyms when a Redactor is provided.
+func FprintLabelStats(out io.Writer, stats *LabelStats, rd *redact.Redactor) {
+	fmt.Fprintf(out, "%s%s %s\n\n", blue("Names analyzed: "), yellow(strconv.Itoa(stats.Names)),
+		green(fmt.Sprintf("(%d unique labels)", len(stats.Labels))))
+
+	fmt.Fprintln(out, blue("Most frequent labels:"))
+	for _, label := range limitKeys(rankedKeys(stats.Labels), topLabelsLimit) {
+		fmt.Fprintf(out, "\t%s %s\n", yellow(fmt.Sprintf("%-6d", stats.Labels[label])), green(rd.Label(label)))
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, blue("Depth distribution:"))
+	depths := make([]int, 0, len(stats.Depths))
+	for d := range stats.Depths {
+		depths = append(depths, d)
+	}
+	sort.Ints(depths)
+	for _, d := range depths {
+		fmt.Fprintf(out, "\t%s %s\n", yellow(fmt.Sprintf("%-6d", stats.Depths[d])),
+			green(fmt.Sprintf("name(s) with %d label(s) below the root domain", d)))
+	}
+
+	if len(stats.Patterns) == 0 {
+		return
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, blue("Numeric and environment patterns:"))
+	for _, p := range limitKeys(rankedKeys(stats.Patterns), topLabelsLimit) {
+		fmt.Fprintf(out, "\t%s %s\n", yellow(fmt.Sprintf("%-6d", stats.Patterns[p])), green(redactPattern(p, rd)))
+	}
+}
+
+// redactPattern replaces the label prefix of a numeric pattern with its pseudonym. The environment
+// labels are kept, since they are the same for every organization and do not reveal the target.
+func redactPattern(p string, rd *redact.Redactor) string {
+	if rd == nil || !strings.HasSuffix(p, "#") {
+		return p
+	}
+
+	prefix := strings.TrimRight(strings.TrimRight(p, "#"), "-_")
+	if isEnvironmentLabel(prefix) {
+		return p
+	}
+	return rd.Label(prefix) + p[len(prefix):]
+}
+
+// WriteWordlist writes the frequency-ranked labels to the file, one per line.
+func WriteWordlist(path string, stats *LabelStats) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, label := range stats.Wordlist() {
+		if _, err := w.WriteString(label + "\n"); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func rankedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func limitKeys(keys []string, limit int) []string {
+	if len(keys) > limit {
+		return keys[:limit]
+	}
+	return keys
+}
@@ -0,0 +1,74 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelPatterns(t *testing.T) {
+	tests := []struct {
+		label    string
+		expected []string
+	}{
+		{"dev01", []string{"dev##"}},
+		{"web-001", []string{"web-###"}},
+		{"node_7", []string{"node_#"}},
+		{"stg-api", []string{"stg-*"}},
+		{"api-prod", []string{"*-prod"}},
+		{"dev_db_qa", []string{"dev_*", "*_qa"}},
+		{"prod2-api", []string{"prod-*"}},
+		{"uat-app-03", []string{"uat-app-##", "uat-*"}},
+		{"www", nil},
+		{"123", nil},
+		{"api-v2", []string{"api-v#"}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, labelPatterns(test.label), test.label)
+	}
+}
+
+func TestAnalyzeLabels(t *testing.T) {
+	stats := AnalyzeLabels([]string{
+		"dev01.example.com", "DEV02.example.com.", "www.example.com", "a.www.example.com",
+		"example.com", "api.eu.example.com", "www.example.net",
+	}, []string{"example.com", "eu.example.com"})
+
+	assert.Equal(t, 5, stats.Names)
+	assert.Equal(t, map[int]int{1: 4, 2: 1}, stats.Depths)
+	assert.Equal(t, map[string]int{"dev01": 1, "dev02": 1, "www": 2, "a": 1, "api": 1}, stats.Labels)
+	assert.Equal(t, map[string]int{"dev##": 2}, stats.Patterns)
+	assert.Equal(t, []string{"www", "a", "api", "dev01", "dev02"}, stats.Wordlist())
+}
+
+func TestFprintLabelStatsDemo(t *testing.T) {
+	status := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = status }()
+
+	rd := redact.NewRedactor([]byte("labelstest"))
+	stats := AnalyzeLabels([]string{"payroll01.labelstest.domain", "dev01.labelstest.domain",
+		"stg-api.labelstest.domain"}, []string{"labelstest.domain"})
+
+	var buf bytes.Buffer
+	FprintLabelStats(&buf, stats, rd)
+
+	out := buf.String()
+	assert.NotContains(t, out, "payroll")
+	assert.NotContains(t, out, "api")
+	assert.Contains(t, out, rd.Label("payroll")+"##")
+	assert.Contains(t, out, "dev##")
+	assert.Contains(t, out, "stg-*")
+
+	assert.Equal(t, rd.Label("web-app")+"-###", redactPattern("web-app-###", rd))
+	assert.Equal(t, "web-app-###", redactPattern("web-app-###", nil))
+	assert.Equal(t, "*_qa", redactPattern("*_qa", rd))
+}
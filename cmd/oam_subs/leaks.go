@@ -0,0 +1,62 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/owasp-amass/oam-tools/viz"
+)
+
+// FprintAddressLeaks writes each name resolving to internal address space, followed by its CNAME
+// chain and the address records that leak the internal addresses.
+func FprintAddressLeaks(out io.Writer, leaks []*viz.AddressLeak, rd *redact.Redactor) {
+	if len(leaks) == 0 {
+		fmt.Fprintln(out, blue("No names resolving to internal addresses were found"))
+		return
+	}
+
+	for _, l := range leaks {
+		fmt.Fprintf(out, "%s %s\n", green(rd.Domain(l.Name)), blue("(last seen "+formatLastSeen(l.LastSeen)+")"))
+
+		for _, link := range l.Chain {
+			fmt.Fprintf(out, "\t%s %s %s\n", blue("CNAME"), yellow(rd.Domain(link.Name)),
+				blue("(last seen "+formatLastSeen(link.LastSeen)+")"))
+		}
+		for _, a := range l.Addresses {
+			fmt.Fprintf(out, "\t%s %s %s %s\n", blue(fmt.Sprintf("%-5s", a.Record)), r.Sprint(leakedAddress(a, rd)),
+				yellow(a.Category+" "+a.Range), blue("(last seen "+formatLastSeen(a.LastSeen)+")"))
+		}
+	}
+}
+
+// leakedAddress returns the pseudonym of the address moved back within its internal range,
+// so that the redacted output remains consistent with the category of the address.
+func leakedAddress(a *viz.InternalAddress, rd *redact.Redactor) string {
+	addr, err := netip.ParseAddr(a.Address)
+	if rd == nil || err != nil {
+		return rd.IP(a.Address)
+	}
+	prefix, err := netip.ParsePrefix(a.Range)
+	if err != nil {
+		return rd.IP(a.Address)
+	}
+
+	pseudo := rd.Addr(addr).AsSlice()
+	base := prefix.Masked().Addr().AsSlice()
+	if len(pseudo) != len(base) {
+		return rd.IP(a.Address)
+	}
+
+	for i := 0; i < prefix.Bits(); i++ {
+		mask := byte(0x80 >> (i % 8))
+		pseudo[i/8] = pseudo[i/8]&^mask | base[i/8]&mask
+	}
+	result, _ := netip.AddrFromSlice(pseudo)
+	return result.String()
+}
@@ -39,10 +39,11 @@ import (
 	"github.com/fatih/color"
 	"github.com/owasp-amass/config/config"
 	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/owasp-amass/oam-tools/requests"
+	"github.com/owasp-amass/oam-tools/viz"
 	oam "github.com/owasp-amass/open-asset-model"
 	"github.com/owasp-amass/open-asset-model/domain"
-	"github.com/owasp-amass/open-asset-model/network"
-	oamreg "github.com/owasp-amass/open-asset-model/registration"
 )
 
 const (
@@ -56,27 +57,42 @@ var (
 
 type dbArgs struct {
 	Domains *stringset.Set
+	ASNData *stringset.Set
 	Enum    int
 	Options struct {
 		DemoMode        bool
+		DemoKey         string
+		Dependencies    bool
 		IPs             bool
 		IPv4            bool
 		IPv6            bool
+		Labels          bool
+		Leaks           bool
+		Records         bool
 		ASNTableSummary bool
 		DiscoveredNames bool
 		NoColor         bool
 		ShowAll         bool
 		Silent          bool
+		Sort            string
+		SummarySort     string
+		Takeover        bool
+		Tree            bool
 	}
 	Filepaths struct {
-		ConfigFile string
-		Directory  string
-		Domains    string
-		TermOut    string
+		ASNCache      string
+		ConfigFile    string
+		Directory     string
+		Domains       string
+		JSONOutput    string
+		TakeoverList  string
+		TakeoverSARIF string
+		TermOut       string
+		Wordlist      string
 	}
 }
 
-type outLookup map[string]*Output
+type outLookup map[string]*requests.Output
 
 func main() {
 	var args dbArgs
@@ -85,6 +101,8 @@ func main() {
 
 	args.Domains = stringset.New()
 	defer args.Domains.Close()
+	args.ASNData = stringset.New()
+	defer args.ASNData.Close()
 
 	dbBuf := new(bytes.Buffer)
 	dbCommand.SetOutput(dbBuf)
@@ -92,19 +110,34 @@ func main() {
 	dbCommand.BoolVar(&help1, "h", false, "Show the program usage message")
 	dbCommand.BoolVar(&help2, "help", false, "Show the program usage message")
 	dbCommand.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
-	dbCommand.BoolVar(&args.Options.DemoMode, "demo", false, "Censor output to make it suitable for demonstrations")
+	dbCommand.BoolVar(&args.Options.DemoMode, "demo", false, "Replace names, addresses, ASNs and organizations with pseudonyms suitable for demonstrations")
+	dbCommand.StringVar(&args.Options.DemoKey, "demokey", "", "Key used to derive the -demo pseudonyms, keeping them consistent across runs and tools")
+	dbCommand.BoolVar(&args.Options.Dependencies, "deps", false, "Print the out-of-scope domains, organizations and ASNs relied on by the in-scope names")
 	dbCommand.BoolVar(&args.Options.IPs, "ip", false, "Show the IP addresses for discovered names")
 	dbCommand.BoolVar(&args.Options.IPv4, "ipv4", false, "Show the IPv4 addresses for discovered names")
 	dbCommand.BoolVar(&args.Options.IPv6, "ipv6", false, "Show the IPv6 addresses for discovered names")
+	dbCommand.BoolVar(&args.Options.Labels, "labels", false, "Print the subdomain label statistics")
+	dbCommand.BoolVar(&args.Options.Leaks, "leaks", false, "Print the names resolving to private, loopback, CGNAT, link-local or ULA addresses")
+	dbCommand.BoolVar(&args.Options.Records, "records", false, "Print the DNS records stored for the discovered names")
 	dbCommand.BoolVar(&args.Options.ASNTableSummary, "summary", false, "Print Just ASN Table Summary")
+	dbCommand.StringVar(&args.Options.Sort, "sort", requests.SortByName, "Order of the discovered names: "+strings.Join(requests.SortOrders, ", "))
+	dbCommand.StringVar(&args.Options.SummarySort, "sortsummary", SummarySortNames, "Order of the ASN table summary: names, ips or asn")
+	dbCommand.BoolVar(&args.Options.Takeover, "takeover", false, "Print the names with dangling CNAME records or takeover-prone targets")
+	dbCommand.BoolVar(&args.Options.Tree, "tree", false, "Print the discovered names as a label hierarchy under each root domain")
 	dbCommand.BoolVar(&args.Options.DiscoveredNames, "names", false, "Print Just Discovered Names")
 	dbCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
 	dbCommand.BoolVar(&args.Options.ShowAll, "show", false, "Print the results for the enumeration index + domains provided")
 	dbCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	dbCommand.StringVar(&args.Filepaths.ASNCache, "asncache", "", "Path to the file used to persist the ASN cache between runs")
+	dbCommand.Var(args.ASNData, "asndata", "Offline IP-to-ASN datasets (iptoasn TSV or RouteViews pfx2as) separated by commas")
 	dbCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file. Additional details below")
 	dbCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
 	dbCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing root domain names")
+	dbCommand.StringVar(&args.Filepaths.JSONOutput, "json", "", "Path to the JSON output file containing the DNS records")
+	dbCommand.StringVar(&args.Filepaths.TakeoverList, "takeoverlist", "", "Path to a file providing additional takeover-prone service suffixes")
+	dbCommand.StringVar(&args.Filepaths.TakeoverSARIF, "takeoversarif", "", "Path to the SARIF 2.1.0 output file containing the takeover candidates")
 	dbCommand.StringVar(&args.Filepaths.TermOut, "o", "", "Path to the text file containing terminal stdout/stderr")
+	dbCommand.StringVar(&args.Filepaths.Wordlist, "wordlist", "", "Path to the wordlist file generated from the subdomain labels")
 
 	var usage = func() {
 		g.Fprintf(color.Error, "Usage: %s %s\n\n", path.Base(os.Args[0]), dbUsageMsg)
@@ -131,6 +164,20 @@ func main() {
 		color.Output = io.Discard
 		color.Error = io.Discard
 	}
+	if !requests.ValidSortOrder(args.Options.Sort) {
+		r.Fprintf(color.Error, "%s is not a valid order for the names\n", args.Options.Sort)
+		os.Exit(1)
+	}
+	switch args.Options.SummarySort {
+	case SummarySortASN, SummarySortNames, SummarySortIPs:
+	default:
+		r.Fprintf(color.Error, "%s is not a valid summary order\n", args.Options.SummarySort)
+		os.Exit(1)
+	}
+	if args.Options.DemoMode && args.Filepaths.Wordlist != "" {
+		r.Fprintln(color.Error, "The wordlist cannot be written in demo mode, since it contains the real labels")
+		os.Exit(1)
+	}
 	if args.Options.IPs {
 		args.Options.IPv4 = true
 		args.Options.IPv6 = true
@@ -168,7 +215,11 @@ func main() {
 		args.Options.DiscoveredNames = true
 		args.Options.ASNTableSummary = true
 	}
-	if !args.Options.DiscoveredNames && !args.Options.ASNTableSummary {
+	if !args.Options.DiscoveredNames && !args.Options.ASNTableSummary &&
+		!args.Options.Tree && !args.Options.Labels && args.Filepaths.Wordlist == "" &&
+		!args.Options.Records && args.Filepaths.JSONOutput == "" &&
+		!args.Options.Takeover && args.Filepaths.TakeoverSARIF == "" && !args.Options.Dependencies &&
+		!args.Options.Leaks {
 		usage()
 		return
 	}
@@ -178,15 +229,23 @@ func main() {
 		asninfo = true
 	}
 
-	showData(&args, asninfo, db)
+	if showData(&args, asninfo, db) {
+		os.Exit(1)
+	}
 }
 
-func showData(args *dbArgs, asninfo bool, db *graph.Graph) {
+// showData prints the requested reports and returns true when an input could not be loaded or an output file could not be written.
+func showData(args *dbArgs, asninfo bool, db *graph.Graph) (failed bool) {
 	var total int
 	var err error
 	var outfile *os.File
 	domains := args.Domains.Slice()
 
+	var rd *redact.Redactor
+	if args.Options.DemoMode {
+		rd = redact.NewRedactor([]byte(args.Options.DemoKey))
+	}
+
 	if args.Filepaths.TermOut != "" {
 		outfile, err = os.OpenFile(args.Filepaths.TermOut, os.O_WRONLY|os.O_CREATE, 0644)
 		if err != nil {
@@ -201,12 +260,12 @@ func showData(args *dbArgs, asninfo bool, db *graph.Graph) {
 		_, _ = outfile.Seek(0, 0)
 	}
 
-	var cache *ASNCache
+	var cache *requests.ASNCache
 	if asninfo {
-		cache = NewASNCache()
-		if err := fillCache(cache, db); err != nil {
+		cache, err = buildCache(args, db)
+		if err != nil {
 			r.Printf("Failed to populate the ASN cache: %v\n", err)
-			return
+			return true
 		}
 	}
 
@@ -214,10 +273,12 @@ func showData(args *dbArgs, asninfo bool, db *graph.Graph) {
 	if len(names) != 0 && (asninfo || args.Options.IPv4 || args.Options.IPv6) {
 		names = addAddresses(context.Background(), db, names, asninfo, cache)
 	}
+	requests.SortOutput(names, args.Options.Sort)
 
+	var shown []*requests.Output
 	asns := make(map[int]*ASNSummaryData)
 	for _, out := range names {
-		if len(domains) > 0 && !domainNameInScope(out.Name, domains) {
+		if len(domains) > 0 && !requests.DomainNameInScope(out.Name, domains) {
 			continue
 		}
 
@@ -232,12 +293,13 @@ func showData(args *dbArgs, asninfo bool, db *graph.Graph) {
 		}
 
 		total++
-		name, ips := OutputLineParts(out, args.Options.IPv4 || args.Options.IPv6, args.Options.DemoMode)
+		shown = append(shown, out)
+		name, ips := OutputLineParts(out, args.Options.IPv4 || args.Options.IPv6, rd)
 		if ips != "" {
 			ips = " " + ips
 		}
 
-		if args.Options.DiscoveredNames {
+		if args.Options.DiscoveredNames && !args.Options.Tree {
 			var written bool
 			if outfile != nil {
 				fmt.Fprintf(outfile, "%s%s\n", name, ips)
@@ -253,6 +315,125 @@ func showData(args *dbArgs, asninfo bool, db *graph.Graph) {
 		r.Println("No names were discovered")
 		return
 	}
+	if args.Options.Tree {
+		var out io.Writer = color.Output
+		status := color.NoColor
+
+		if outfile != nil {
+			out = outfile
+			color.NoColor = true
+		}
+
+		for _, t := range BuildNameTrees(shown, domains) {
+			FprintNameTree(out, t, args.Options.IPv4 || args.Options.IPv6, rd)
+		}
+		color.NoColor = status
+	}
+	if args.Options.Records || args.Filepaths.JSONOutput != "" {
+		var list []string
+		for _, out := range shown {
+			list = append(list, out.Name)
+		}
+		records := RedactDNSRecords(GetDNSRecords(db, list, time.Time{}), rd)
+
+		if args.Options.Records {
+			var out io.Writer = color.Output
+			status := color.NoColor
+
+			if outfile != nil {
+				out = outfile
+				color.NoColor = true
+			}
+			FprintDNSRecords(out, records)
+			color.NoColor = status
+		}
+		if args.Filepaths.JSONOutput != "" {
+			if err := WriteDNSRecordsJSON(args.Filepaths.JSONOutput, records); err != nil {
+				r.Fprintf(color.Error, "Failed to write the JSON output file: %v\n", err)
+				failed = true
+			}
+		}
+	}
+	if args.Options.Takeover || args.Filepaths.TakeoverSARIF != "" {
+		var list []string
+		if args.Filepaths.TakeoverList != "" {
+			list, err = config.GetListFromFile(args.Filepaths.TakeoverList)
+			if err != nil {
+				r.Fprintf(color.Error, "Failed to parse the takeover suffixes file: %v\n", err)
+				return true
+			}
+		}
+		candidates := viz.TakeoverCandidates(domains, takeoverSuffixes(list), time.Time{}, db)
+
+		if args.Options.Takeover {
+			var out io.Writer = color.Output
+			status := color.NoColor
+
+			if outfile != nil {
+				out = outfile
+				color.NoColor = true
+			}
+			FprintTakeoverCandidates(out, candidates, rd)
+			color.NoColor = status
+		}
+		if args.Filepaths.TakeoverSARIF != "" {
+			if err := WriteTakeoverSARIF(args.Filepaths.TakeoverSARIF, candidates, rd); err != nil {
+				r.Fprintf(color.Error, "Failed to write the SARIF output file: %v\n", err)
+				failed = true
+			}
+		}
+	}
+	if args.Options.Leaks {
+		leaks := viz.AddressLeaks(domains, time.Time{}, db)
+
+		var out io.Writer = color.Output
+		status := color.NoColor
+
+		if outfile != nil {
+			out = outfile
+			color.NoColor = true
+		}
+		FprintAddressLeaks(out, leaks, rd)
+		color.NoColor = status
+	}
+	if args.Options.Dependencies {
+		report := viz.Dependencies(domains, time.Time{}, db)
+
+		var out io.Writer = color.Output
+		status := color.NoColor
+
+		if outfile != nil {
+			out = outfile
+			color.NoColor = true
+		}
+		FprintDependencies(out, report, rd)
+		color.NoColor = status
+	}
+	if args.Options.Labels || args.Filepaths.Wordlist != "" {
+		var list []string
+		for _, out := range shown {
+			list = append(list, out.Name)
+		}
+		stats := AnalyzeLabels(list, domains)
+
+		if args.Options.Labels {
+			var out io.Writer = color.Output
+			status := color.NoColor
+
+			if outfile != nil {
+				out = outfile
+				color.NoColor = true
+			}
+			FprintLabelStats(out, stats, rd)
+			color.NoColor = status
+		}
+		if args.Filepaths.Wordlist != "" {
+			if err := WriteWordlist(args.Filepaths.Wordlist, stats); err != nil {
+				r.Fprintf(color.Error, "Failed to write the wordlist file: %v\n", err)
+				failed = true
+			}
+		}
+	}
 	if args.Options.ASNTableSummary {
 		var out io.Writer
 		status := color.NoColor
@@ -266,9 +447,10 @@ func showData(args *dbArgs, asninfo bool, db *graph.Graph) {
 			out = color.Output
 		}
 
-		FprintEnumerationSummary(out, total, asns, args.Options.DemoMode)
+		FprintEnumerationSummary(out, total, asns, args.Options.SummarySort, rd)
 		color.NoColor = status
 	}
+	return failed
 }
 
 func openGraphDatabase(cfg *config.Config) *graph.Graph {
@@ -296,7 +478,7 @@ func openGraphDatabase(cfg *config.Config) *graph.Graph {
 	return graph.NewGraph("memory", "", "")
 }
 
-func getNames(ctx context.Context, domains []string, asninfo bool, g *graph.Graph) []*Output {
+func getNames(ctx context.Context, domains []string, asninfo bool, g *graph.Graph) []*requests.Output {
 	if len(domains) == 0 {
 		return nil
 	}
@@ -315,17 +497,17 @@ func getNames(ctx context.Context, domains []string, asninfo bool, g *graph.Grap
 		return nil
 	}
 
-	var names []*Output
+	var names []*requests.Output
 	for _, a := range assets {
 		if n, ok := a.Asset.(*domain.FQDN); ok && !filter.Has(n.Name) {
-			names = append(names, &Output{Name: n.Name})
+			names = append(names, &requests.Output{Name: n.Name})
 			filter.Insert(n.Name)
 		}
 	}
 	return names
 }
 
-func addAddresses(ctx context.Context, g *graph.Graph, names []*Output, asninfo bool, cache *ASNCache) []*Output {
+func addAddresses(ctx context.Context, g *graph.Graph, names []*requests.Output, asninfo bool, cache *requests.ASNCache) []*requests.Output {
 	var namestrs []string
 	lookup := make(outLookup, len(names))
 	for _, n := range names {
@@ -342,13 +524,13 @@ func addAddresses(ctx context.Context, g *graph.Graph, names []*Output, asninfo
 				continue
 			}
 			if o, found := lookup[p.FQDN.Name]; found {
-				o.Addresses = append(o.Addresses, AddressInfo{Address: net.ParseIP(addr)})
+				o.Addresses = append(o.Addresses, requests.AddressInfo{Address: net.ParseIP(addr)})
 			}
 		}
 	}
 
 	if !asninfo || cache == nil {
-		var output []*Output
+		var output []*requests.Output
 		for _, o := range lookup {
 			if len(o.Addresses) > 0 {
 				output = append(output, o)
@@ -359,27 +541,11 @@ func addAddresses(ctx context.Context, g *graph.Graph, names []*Output, asninfo
 	return addInfrastructureInfo(lookup, cache)
 }
 
-func domainNameInScope(name string, scope []string) bool {
-	var discovered bool
-
-	n := strings.ToLower(strings.TrimSpace(name))
-	for _, d := range scope {
-		d = strings.ToLower(d)
-
-		if n == d || strings.HasSuffix(n, "."+d) {
-			discovered = true
-			break
-		}
-	}
-
-	return discovered
-}
-
-func addInfrastructureInfo(lookup outLookup, cache *ASNCache) []*Output {
-	output := make([]*Output, 0, len(lookup))
+func addInfrastructureInfo(lookup outLookup, cache *requests.ASNCache) []*requests.Output {
+	output := make([]*requests.Output, 0, len(lookup))
 
 	for _, o := range lookup {
-		var newaddrs []AddressInfo
+		var newaddrs []requests.AddressInfo
 
 		for _, a := range o.Addresses {
 			i := cache.AddrSearch(a.Address.String())
@@ -388,12 +554,15 @@ func addInfrastructureInfo(lookup outLookup, cache *ASNCache) []*Output {
 			}
 
 			_, netblock, _ := net.ParseCIDR(i.Prefix)
-			newaddrs = append(newaddrs, AddressInfo{
-				Address:     a.Address,
-				ASN:         i.ASN,
-				CIDRStr:     i.Prefix,
-				Netblock:    netblock,
-				Description: i.Description,
+			newaddrs = append(newaddrs, requests.AddressInfo{
+				Address:        a.Address,
+				ASN:            i.ASN,
+				CIDRStr:        i.Prefix,
+				Netblock:       netblock,
+				Description:    i.Description,
+				CC:             i.CC,
+				Registry:       i.Registry,
+				AllocationDate: i.AllocationDate,
 			})
 		}
 
@@ -405,53 +574,43 @@ func addInfrastructureInfo(lookup outLookup, cache *ASNCache) []*Output {
 	return output
 }
 
-func fillCache(cache *ASNCache, db *graph.Graph) error {
-	start := time.Now().Add(-730 * time.Hour)
-	assets, err := db.DB.FindByType(oam.AutonomousSystem, start)
-	if err != nil {
-		return err
-	}
+func buildCache(args *dbArgs, db *graph.Graph) (*requests.ASNCache, error) {
+	cache := requests.NewASNCache()
 
-	for _, a := range assets {
-		as, ok := a.Asset.(*network.AutonomousSystem)
-		if !ok {
-			continue
+	if args.Filepaths.ASNCache != "" {
+		if f, err := os.Open(args.Filepaths.ASNCache); err == nil {
+			err = cache.Load(f)
+			_ = f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load %s: %v", args.Filepaths.ASNCache, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, err
 		}
+	}
 
-		var desc string
-		rels, err := db.DB.OutgoingRelations(a, start, "registration")
-		if err != nil || len(rels) == 0 {
-			continue
+	for _, path := range args.ASNData.Slice() {
+		if _, err := requests.ImportASNDataset(cache, path); err != nil {
+			return nil, fmt.Errorf("failed to import %s: %v", path, err)
 		}
+	}
 
-		for _, rel := range rels {
-			if asset, err := db.DB.FindById(rel.ToAsset.ID, start); err == nil && asset != nil {
-				if autnum, ok := asset.Asset.(*oamreg.AutnumRecord); ok && autnum != nil {
-					desc = autnum.Handle + " - " + autnum.Name
-					break
-				}
-			}
-		}
-		if desc == "" {
-			continue
-		}
+	if err := requests.FillCache(cache, db); err != nil {
+		return nil, err
+	}
+	// Build the cache once before the addresses are searched
+	cache.Build()
 
-		for _, prefix := range db.ReadASPrefixes(context.Background(), as.Number, start) {
-			first, cidr, err := net.ParseCIDR(prefix)
-			if err != nil {
-				continue
-			}
-			if ones, _ := cidr.Mask.Size(); ones == 0 {
-				continue
-			}
+	if args.Filepaths.ASNCache != "" {
+		f, err := os.OpenFile(args.Filepaths.ASNCache, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
 
-			cache.Update(&ASNRequest{
-				Address:     first.String(),
-				ASN:         as.Number,
-				Prefix:      cidr.String(),
-				Description: desc,
-			})
+		if err := cache.Save(f); err != nil {
+			return nil, fmt.Errorf("failed to save %s: %v", args.Filepaths.ASNCache, err)
 		}
 	}
-	return nil
+	return cache, nil
 }
@@ -0,0 +1,282 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+)
+
+// The maximum number of CNAME records followed from a discovered name
+const maxCNAMEChain = 10
+
+// DNSRecords contains the DNS relations stored in the graph for a discovered name.
+type DNSRecords struct {
+	Name string `json:"name"`
+	// CNAME is the chain of aliases followed from the name
+	CNAME []string `json:"cname,omitempty"`
+	A     []string `json:"a,omitempty"`
+	AAAA  []string `json:"aaaa,omitempty"`
+	NS    []string `json:"ns,omitempty"`
+	MX    []string `json:"mx,omitempty"`
+	SRV   []string `json:"srv,omitempty"`
+	// PTR lists the addresses with a PTR record pointing to the name
+	PTR []string `json:"ptr,omitempty"`
+	// Resolved lists the addresses at the end of the CNAME chain
+	Resolved []string `json:"resolved,omitempty"`
+}
+
+// GetDNSRecords returns the DNS relations stored in the graph for each of the names.
+func GetDNSRecords(g *graph.Graph, names []string, since time.Time) []*DNSRecords {
+	var results []*DNSRecords
+
+	for _, name := range names {
+		assets, err := g.DB.FindByContent(&domain.FQDN{Name: name}, since)
+		if err != nil || len(assets) == 0 {
+			continue
+		}
+
+		fqdn := assets[0]
+		rec := &DNSRecords{Name: name}
+		rec.A = relatedKeys(g, fqdn, "a_record", since)
+		rec.AAAA = relatedKeys(g, fqdn, "aaaa_record", since)
+		rec.NS = relatedKeys(g, fqdn, "ns_record", since)
+		rec.MX = relatedKeys(g, fqdn, "mx_record", since)
+		rec.SRV = relatedKeys(g, fqdn, "srv_record", since)
+		rec.PTR = ptrAddresses(g, fqdn, since)
+
+		final := fqdn
+		seen := map[string]struct{}{fqdn.ID: {}}
+		for i := 0; i < maxCNAMEChain; i++ {
+			next := relatedAssets(g, final, "cname_record", since)
+			if len(next) == 0 {
+				break
+			}
+			if _, found := seen[next[0].ID]; found {
+				break
+			}
+
+			final = next[0]
+			seen[final.ID] = struct{}{}
+			rec.CNAME = append(rec.CNAME, final.Asset.Key())
+		}
+
+		if final == fqdn {
+			rec.Resolved = append(append([]string{}, rec.A...), rec.AAAA...)
+		} else {
+			rec.Resolved = append(relatedKeys(g, final, "a_record", since), relatedKeys(g, final, "aaaa_record", since)...)
+		}
+		results = append(results, rec)
+	}
+	return results
+}
+
+func relatedAssets(g *graph.Graph, a *types.Asset, rtype string, since time.Time) []*types.Asset {
+	var assets []*types.Asset
+
+	rels, err := g.DB.OutgoingRelations(a, since, rtype)
+	if err != nil {
+		return assets
+	}
+
+	for _, rel := range rels {
+		if to, err := g.DB.FindById(rel.ToAsset.ID, since); err == nil && to != nil && to.Asset != nil {
+			assets = append(assets, to)
+		}
+	}
+
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i].Asset.Key() < assets[j].Asset.Key()
+	})
+	return assets
+}
+
+func relatedKeys(g *graph.Graph, a *types.Asset, rtype string, since time.Time) []string {
+	var keys []string
+
+	for _, to := range relatedAssets(g, a, rtype, since) {
+		if ip, ok := to.Asset.(*network.IPAddress); ok {
+			keys = append(keys, ip.Address.String())
+		} else {
+			keys = append(keys, to.Asset.Key())
+		}
+	}
+	return keys
+}
+
+func ptrAddresses(g *graph.Graph, a *types.Asset, since time.Time) []string {
+	var addrs []string
+
+	rels, err := g.DB.IncomingRelations(a, since, "ptr_record")
+	if err != nil {
+		return addrs
+	}
+
+	for _, rel := range rels {
+		from, err := g.DB.FindById(rel.FromAsset.ID, since)
+		if err != nil || from == nil || from.Asset == nil {
+			continue
+		}
+
+		if addr, ok := reverseNameToAddr(from.Asset.Key()); ok {
+			addrs = append(addrs, addr)
+		} else {
+			addrs = append(addrs, from.Asset.Key())
+		}
+	}
+
+	sort.Strings(addrs)
+	return addrs
+}
+
+// reverseNameToAddr converts an in-addr.arpa or ip6.arpa name to the address it represents.
+func reverseNameToAddr(name string) (string, bool) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+
+	if rest, found := strings.CutSuffix(name, ".in-addr.arpa"); found {
+		octets := strings.Split(rest, ".")
+		if len(octets) != 4 {
+			return "", false
+		}
+
+		var b [4]byte
+		for i, o := range octets {
+			v, err := strconv.ParseUint(o, 10, 8)
+			if err != nil {
+				return "", false
+			}
+			b[3-i] = byte(v)
+		}
+		return netip.AddrFrom4(b).String(), true
+	}
+
+	if rest, found := strings.CutSuffix(name, ".ip6.arpa"); found {
+		nibbles := strings.Split(rest, ".")
+		if len(nibbles) != 32 {
+			return "", false
+		}
+
+		var b [16]byte
+		for i, n := range nibbles {
+			v, err := strconv.ParseUint(n, 16, 4)
+			if err != nil {
+				return "", false
+			}
+
+			pos := 31 - i
+			if pos%2 == 0 {
+				b[pos/2] |= byte(v) << 4
+			} else {
+				b[pos/2] |= byte(v)
+			}
+		}
+		return netip.AddrFrom16(b).String(), true
+	}
+	return "", false
+}
+
+// FprintDNSRecords writes each name followed by its DNS records.
+func FprintDNSRecords(out io.Writer, records []*DNSRecords) {
+	for _, rec := range records {
+		fmt.Fprintln(out, green(rec.Name))
+
+		fprintRecordLine(out, "CNAME", strings.Join(rec.CNAME, " -> "))
+		fprintRecordLine(out, "A", strings.Join(rec.A, ","))
+		fprintRecordLine(out, "AAAA", strings.Join(rec.AAAA, ","))
+		fprintRecordLine(out, "NS", strings.Join(rec.NS, ","))
+		fprintRecordLine(out, "MX", strings.Join(rec.MX, ","))
+		fprintRecordLine(out, "SRV", strings.Join(rec.SRV, ","))
+		fprintRecordLine(out, "PTR", strings.Join(rec.PTR, ","))
+		if len(rec.CNAME) > 0 {
+			fprintRecordLine(out, "Resolved", strings.Join(rec.Resolved, ","))
+		}
+	}
+}
+
+// RedactDNSRecords returns copies of the records with the names and addresses replaced by their pseudonyms.
+func RedactDNSRecords(records []*DNSRecords, rd *redact.Redactor) []*DNSRecords {
+	if rd == nil {
+		return records
+	}
+
+	names := func(list []string) []string {
+		var results []string
+		for _, n := range list {
+			results = append(results, rd.Domain(n))
+		}
+		return results
+	}
+	addrs := func(list []string) []string {
+		var results []string
+		for _, a := range list {
+			results = append(results, rd.IP(a))
+		}
+		return results
+	}
+	// SRV records can point to either a name or an address
+	targets := func(list []string) []string {
+		var results []string
+		for _, t := range list {
+			if _, err := netip.ParseAddr(t); err == nil {
+				results = append(results, rd.IP(t))
+			} else {
+				results = append(results, rd.Domain(t))
+			}
+		}
+		return results
+	}
+
+	var results []*DNSRecords
+	for _, rec := range records {
+		results = append(results, &DNSRecords{
+			Name:     rd.Domain(rec.Name),
+			CNAME:    names(rec.CNAME),
+			A:        addrs(rec.A),
+			AAAA:     addrs(rec.AAAA),
+			NS:       names(rec.NS),
+			MX:       names(rec.MX),
+			SRV:      targets(rec.SRV),
+			PTR:      addrs(rec.PTR),
+			Resolved: addrs(rec.Resolved),
+		})
+	}
+	return results
+}
+
+func fprintRecordLine(out io.Writer, rrtype, value string) {
+	if value != "" {
+		fmt.Fprintf(out, "\t%s %s\n", blue(fmt.Sprintf("%-9s", rrtype)), yellow(value))
+	}
+}
+
+// WriteDNSRecordsJSON writes the DNS records to the file as a JSON array.
+func WriteDNSRecordsJSON(path string, records []*DNSRecords) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if records == nil {
+		records = []*DNSRecords{}
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
@@ -0,0 +1,113 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseNameToAddr(t *testing.T) {
+	v6 := "b.a.9.8.7.6.5.0.4.0.0.0.3.0.0.0.2.0.0.0.1.0.0.0.0.0.0.0.1.2.3.4.ip6.arpa"
+
+	tests := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{"34.216.184.93.in-addr.arpa", "93.184.216.34", true},
+		{"34.216.184.93.IN-ADDR.ARPA.", "93.184.216.34", true},
+		{"0.0.0.0.in-addr.arpa", "0.0.0.0", true},
+		{v6, "4321:0:1:2:3:4:567:89ab", true},
+		{strings.ToUpper(v6) + ".", "4321:0:1:2:3:4:567:89ab", true},
+		{"216.184.93.in-addr.arpa", "", false},
+		{"256.216.184.93.in-addr.arpa", "", false},
+		{"a.216.184.93.in-addr.arpa", "", false},
+		{"-1.216.184.93.in-addr.arpa", "", false},
+		{strings.TrimPrefix(v6, "b."), "", false},
+		{"10" + strings.TrimPrefix(v6, "b"), "", false},
+		{"g" + strings.TrimPrefix(v6, "b"), "", false},
+		{"www.example.com", "", false},
+		{"in-addr.arpa", "", false},
+	}
+
+	for _, test := range tests {
+		addr, ok := reverseNameToAddr(test.name)
+
+		assert.Equal(t, test.ok, ok, test.name)
+		assert.Equal(t, test.expected, addr, test.name)
+	}
+}
+
+func TestGetDNSRecords(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+
+	_, err := g.UpsertCNAME(ctx, "www.recordstest.domain", "edge.recordstest-cdn.net")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(ctx, "edge.recordstest-cdn.net", "lb.recordstest-cdn.net")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "lb.recordstest-cdn.net", "93.184.216.170")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "mail.recordstest.domain", "93.184.216.171")
+	assert.Nil(t, err)
+	_, err = g.UpsertAAAA(ctx, "mail.recordstest.domain", "2001:db8::171")
+	assert.Nil(t, err)
+	_, err = g.UpsertPTR(ctx, "171.216.184.93.in-addr.arpa", "mail.recordstest.domain")
+	assert.Nil(t, err)
+	_, err = g.UpsertMX(ctx, "recordstest.domain", "mail.recordstest.domain")
+	assert.Nil(t, err)
+
+	// A CNAME loop is followed once
+	_, err = g.UpsertCNAME(ctx, "loop1.recordstest.domain", "loop2.recordstest.domain")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(ctx, "loop2.recordstest.domain", "loop1.recordstest.domain")
+	assert.Nil(t, err)
+
+	records := GetDNSRecords(g, []string{"www.recordstest.domain", "mail.recordstest.domain",
+		"recordstest.domain", "loop1.recordstest.domain", "missing.recordstest.domain"}, time.Time{})
+
+	if assert.Len(t, records, 4) {
+		rec := records[0]
+		assert.Equal(t, []string{"edge.recordstest-cdn.net", "lb.recordstest-cdn.net"}, rec.CNAME)
+		assert.Empty(t, rec.A)
+		assert.Equal(t, []string{"93.184.216.170"}, rec.Resolved)
+
+		rec = records[1]
+		assert.Equal(t, []string{"93.184.216.171"}, rec.A)
+		assert.Equal(t, []string{"2001:db8::171"}, rec.AAAA)
+		assert.Equal(t, []string{"93.184.216.171"}, rec.PTR)
+		assert.Equal(t, []string{"93.184.216.171", "2001:db8::171"}, rec.Resolved)
+
+		assert.Equal(t, []string{"mail.recordstest.domain"}, records[2].MX)
+		assert.Equal(t, []string{"loop2.recordstest.domain"}, records[3].CNAME)
+	}
+}
+
+func TestRedactDNSRecords(t *testing.T) {
+	rd := redact.NewRedactor([]byte("recordstest"))
+	records := []*DNSRecords{{
+		Name: "_sip._tcp.recordstest.domain",
+		A:    []string{"93.184.216.172"},
+		SRV:  []string{"sip.recordstest.domain", "93.184.216.172", "2001:db8::172"},
+	}}
+
+	redacted := RedactDNSRecords(records, rd)
+	if assert.Len(t, redacted, 1) {
+		rec := redacted[0]
+		assert.Equal(t, rd.Domain("_sip._tcp.recordstest.domain"), rec.Name)
+		// Address targets get the same prefix-preserving pseudonyms as the address records
+		assert.Equal(t, []string{rd.Domain("sip.recordstest.domain"), rec.A[0], rd.IP("2001:db8::172")}, rec.SRV)
+		assert.NotEqual(t, "93.184.216.172", rec.A[0])
+	}
+
+	assert.Equal(t, records, RedactDNSRecords(records, nil))
+}
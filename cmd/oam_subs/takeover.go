@@ -0,0 +1,98 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/oam-tools/findings"
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/owasp-amass/oam-tools/viz"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+// takeoverSuffixes returns the built-in takeover-prone service suffixes extended by the entries of the list.
+func takeoverSuffixes(list []string) []string {
+	suffixes := append([]string{}, viz.TakeoverSuffixes...)
+
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" && !strings.HasPrefix(s, "#") {
+			suffixes = append(suffixes, s)
+		}
+	}
+	return suffixes
+}
+
+// FprintTakeoverCandidates writes each candidate followed by its CNAME chain and the reasons it was flagged.
+func FprintTakeoverCandidates(out io.Writer, candidates []*viz.TakeoverCandidate, rd *redact.Redactor) {
+	if len(candidates) == 0 {
+		fmt.Fprintln(out, blue("No subdomain takeover candidates were found"))
+		return
+	}
+
+	for _, c := range candidates {
+		fmt.Fprintf(out, "%s %s\n", green(rd.Domain(c.Name)), blue("(last seen "+formatLastSeen(c.LastSeen)+")"))
+
+		for _, link := range c.Chain {
+			fmt.Fprintf(out, "\t%s %s %s\n", blue("->"), yellow(rd.Domain(link.Name)), blue("(last seen "+formatLastSeen(link.LastSeen)+")"))
+		}
+
+		var reasons []string
+		if c.Dangling {
+			reasons = append(reasons, "dangling: the target has no address records")
+		}
+		if c.Service != "" {
+			reasons = append(reasons, "takeover-prone service: "+c.Service)
+		}
+		fmt.Fprintf(out, "\t%s\n", r.Sprint(strings.Join(reasons, "; ")))
+	}
+}
+
+// TakeoverFindings reports the dangling CNAME records and takeover-prone services of the candidates
+// as separate findings, with the CNAME chain as evidence.
+func TakeoverFindings(candidates []*viz.TakeoverCandidate, rd *redact.Redactor) []*findings.Finding {
+	var results []*findings.Finding
+
+	for _, c := range candidates {
+		chain := []string{rd.Domain(c.Name)}
+		for _, link := range c.Chain {
+			chain = append(chain, rd.Domain(link.Name))
+		}
+		evidence := []string{"CNAME chain: " + strings.Join(chain, " -> ")}
+
+		if c.Dangling {
+			results = append(results, findings.NewFinding(findings.DanglingCNAMERule, string(oam.FQDN),
+				rd.Domain(c.Name), append(evidence, "the target has no address records")))
+		}
+		if c.Service != "" {
+			results = append(results, findings.NewFinding(findings.TakeoverProneServiceRule, string(oam.FQDN),
+				rd.Domain(c.Name), append(evidence, "takeover-prone service: "+c.Service)))
+		}
+	}
+	return results
+}
+
+// WriteTakeoverSARIF saves the takeover candidates to the SARIF output file.
+func WriteTakeoverSARIF(path string, candidates []*viz.TakeoverCandidate, rd *redact.Redactor) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rules := []*findings.Rule{findings.DanglingCNAMERule, findings.TakeoverProneServiceRule}
+	return findings.WriteSARIF(f, "oam_subs", rules, TakeoverFindings(candidates, rd))
+}
+
+func formatLastSeen(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,177 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/owasp-amass/oam-tools/requests"
+)
+
+// NameTree is a branch of the DNS label hierarchy built from the discovered names.
+type NameTree struct {
+	Label string
+	// Discovered is true when the branch is also a discovered name
+	Discovered bool
+	// Count is the number of discovered names within the branch
+	Count     int
+	Addresses []string
+	Children  map[string]*NameTree
+}
+
+func newNameTree(label string) *NameTree {
+	return &NameTree{
+		Label:    label,
+		Children: make(map[string]*NameTree),
+	}
+}
+
+// BuildNameTrees returns a label hierarchy for each root domain that has discovered names.
+// A name within several root domains is placed under the most specific one.
+func BuildNameTrees(names []*requests.Output, domains []string) []*NameTree {
+	roots := make(map[string]*NameTree)
+
+	for _, out := range names {
+		name := strings.ToLower(strings.TrimSuffix(out.Name, "."))
+
+		root := rootDomain(name, domains)
+		if root == "" {
+			continue
+		}
+
+		t, found := roots[root]
+		if !found {
+			t = newNameTree(root)
+			roots[root] = t
+		}
+
+		var labels []string
+		if name != root {
+			labels = strings.Split(strings.TrimSuffix(name, "."+root), ".")
+		}
+
+		var addrs []string
+		for _, a := range out.Addresses {
+			addrs = append(addrs, a.Address.String())
+		}
+		t.insert(labels, addrs)
+	}
+
+	trees := make([]*NameTree, 0, len(roots))
+	for _, t := range roots {
+		trees = append(trees, t)
+	}
+	sort.Slice(trees, func(i, j int) bool {
+		return trees[i].Label < trees[j].Label
+	})
+	return trees
+}
+
+// insert adds the name made of the labels, ordered left to right, below the branch.
+func (t *NameTree) insert(labels []string, addrs []string) {
+	path := []*NameTree{t}
+
+	for i := len(labels) - 1; i >= 0; i-- {
+		node := path[len(path)-1]
+
+		child, found := node.Children[labels[i]]
+		if !found {
+			child = newNameTree(labels[i])
+			node.Children[labels[i]] = child
+		}
+		path = append(path, child)
+	}
+
+	leaf := path[len(path)-1]
+	if !leaf.Discovered {
+		for _, node := range path {
+			node.Count++
+		}
+	}
+	leaf.Discovered = true
+	leaf.Addresses = append(leaf.Addresses, addrs...)
+}
+
+func (t *NameTree) sortedChildren() []*NameTree {
+	children := make([]*NameTree, 0, len(t.Children))
+	for _, c := range t.Children {
+		children = append(children, c)
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Label < children[j].Label
+	})
+	return children
+}
+
+// FprintNameTree writes the label hierarchy, including the addresses as leaves when addrs is true.
+func FprintNameTree(out io.Writer, t *NameTree, addrs bool, rd *redact.Redactor) {
+	fmt.Fprintf(out, "%s %s\n", green(rd.Domain(t.Label)), yellow("("+strconv.Itoa(t.Count)+")"))
+	fprintTreeBranch(out, t, "", addrs, rd)
+}
+
+func fprintTreeBranch(out io.Writer, t *NameTree, prefix string, addrs bool, rd *redact.Redactor) {
+	var leaves []string
+	if addrs {
+		for _, a := range t.Addresses {
+			leaves = append(leaves, rd.IP(a))
+		}
+	}
+
+	children := t.sortedChildren()
+	num := len(leaves) + len(children)
+	for i, addr := range leaves {
+		fmt.Fprintf(out, "%s%s%s\n", prefix, treeConnector(i == num-1), yellow(addr))
+	}
+
+	for i, c := range children {
+		last := len(leaves)+i == num-1
+
+		// Wildcard labels are kept, the way the redacted names keep them
+		label := c.Label
+		if label != "*" {
+			label = rd.Label(label)
+		}
+		if c.Discovered {
+			label = green(label)
+		} else {
+			label = blue(label)
+		}
+
+		fmt.Fprintf(out, "%s%s%s %s\n", prefix, treeConnector(last), label, yellow("("+strconv.Itoa(c.Count)+")"))
+
+		next := prefix + "│   "
+		if last {
+			next = prefix + "    "
+		}
+		fprintTreeBranch(out, c, next, addrs, rd)
+	}
+}
+
+func treeConnector(last bool) string {
+	if last {
+		return "└── "
+	}
+	return "├── "
+}
+
+// rootDomain returns the most specific root domain that the name belongs to.
+func rootDomain(name string, domains []string) string {
+	var root string
+
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSuffix(d, "."))
+
+		if (name == d || strings.HasSuffix(name, "."+d)) && len(d) > len(root) {
+			root = d
+		}
+	}
+	return root
+}
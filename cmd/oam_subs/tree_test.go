@@ -0,0 +1,147 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/owasp-amass/oam-tools/requests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildNameTrees(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    []string
+		domains  []string
+		expected []string
+	}{
+		{
+			name:     "labels below the root",
+			names:    []string{"www.example.com", "a.b.example.com", "b.example.com."},
+			domains:  []string{"example.com"},
+			expected: []string{"example.com 3", "b.example.com 2 *", "a.b.example.com 1 *", "www.example.com 1 *"},
+		},
+		{
+			name:     "discovered root domain",
+			names:    []string{"example.com", "www.example.com"},
+			domains:  []string{"example.com"},
+			expected: []string{"example.com 2 *", "www.example.com 1 *"},
+		},
+		{
+			name:     "names counted once regardless of case",
+			names:    []string{"WWW.Example.com", "www.example.com"},
+			domains:  []string{"Example.com."},
+			expected: []string{"example.com 1", "www.example.com 1 *"},
+		},
+		{
+			name:    "most specific root domain",
+			names:   []string{"api.eu.example.com", "www.example.com"},
+			domains: []string{"example.com", "eu.example.com", "example.org"},
+			expected: []string{
+				"eu.example.com 1", "api.eu.example.com 1 *",
+				"example.com 1", "www.example.com 1 *",
+			},
+		},
+		{
+			name:     "names outside the domains",
+			names:    []string{"www.example.net", "notexample.com"},
+			domains:  []string{"example.com"},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		var names []*requests.Output
+		for _, n := range test.names {
+			names = append(names, &requests.Output{Name: n})
+		}
+
+		var paths []string
+		for _, tree := range BuildNameTrees(names, test.domains) {
+			paths = append(paths, treePaths(tree, "")...)
+		}
+		assert.Equal(t, test.expected, paths, test.name)
+	}
+}
+
+func TestNameTreeAddresses(t *testing.T) {
+	trees := BuildNameTrees([]*requests.Output{
+		{Name: "www.example.com", Addresses: []requests.AddressInfo{
+			{Address: net.ParseIP("93.184.216.34")},
+			{Address: net.ParseIP("2606:2800:220:1:248:1893:25c8:1946")},
+		}},
+	}, []string{"example.com"})
+
+	if assert.Len(t, trees, 1) && assert.Contains(t, trees[0].Children, "www") {
+		assert.Empty(t, trees[0].Addresses)
+		assert.Equal(t, []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"}, trees[0].Children["www"].Addresses)
+	}
+}
+
+func TestFprintNameTreeRedacted(t *testing.T) {
+	nocolor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = nocolor }()
+
+	rd := redact.NewRedactor([]byte("treetest"))
+	trees := BuildNameTrees([]*requests.Output{
+		{Name: "*.dev.treetest.domain"},
+		{Name: "www.treetest.domain"},
+	}, []string{"treetest.domain"})
+
+	if !assert.Len(t, trees, 1) {
+		return
+	}
+	var buf bytes.Buffer
+	FprintNameTree(&buf, trees[0], false, rd)
+	out := buf.String()
+
+	// The tree shows the same pseudonyms as the redacted names
+	wildcard := strings.Split(rd.Domain("*.dev.treetest.domain"), ".")
+	assert.Equal(t, "*", wildcard[0])
+	assert.Contains(t, out, "── "+wildcard[1]+" (1)")
+	assert.Contains(t, out, "── * (1)")
+	assert.Contains(t, out, "── "+strings.Split(rd.Domain("www.treetest.domain"), ".")[0]+" (1)")
+	assert.NotContains(t, out, "treetest")
+	assert.NotContains(t, out, rd.Label("*"))
+}
+
+func TestRootDomain(t *testing.T) {
+	domains := []string{"example.com", "EU.example.com."}
+
+	for name, expected := range map[string]string{
+		"example.com":              "example.com",
+		"www.example.com":          "example.com",
+		"api.eu.example.com":       "eu.example.com",
+		"eu.example.com":           "eu.example.com",
+		"notexample.com":           "",
+		"www.example.com.evil.net": "",
+	} {
+		assert.Equal(t, expected, rootDomain(name, domains), name)
+	}
+}
+
+// treePaths lists the branches depth first as the name, the count and a mark for discovered names.
+func treePaths(t *NameTree, suffix string) []string {
+	name := t.Label + suffix
+
+	path := name + " " + strconv.Itoa(t.Count)
+	if t.Discovered {
+		path += " *"
+	}
+
+	paths := []string{path}
+	for _, c := range t.sortedChildren() {
+		paths = append(paths, treePaths(c, "."+name)...)
+	}
+	return paths
+}
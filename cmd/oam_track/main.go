@@ -36,6 +36,9 @@ import (
 	"github.com/fatih/color"
 	"github.com/owasp-amass/config/config"
 	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/findings"
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/owasp-amass/oam-tools/requests"
 	oam "github.com/owasp-amass/open-asset-model"
 	"github.com/owasp-amass/open-asset-model/domain"
 )
@@ -55,13 +58,17 @@ type trackArgs struct {
 	Domains *stringset.Set
 	Since   string
 	Options struct {
-		NoColor bool
-		Silent  bool
+		DemoMode bool
+		DemoKey  string
+		NoColor  bool
+		Silent   bool
+		Sort     string
 	}
 	Filepaths struct {
-		ConfigFile string
-		Directory  string
-		Domains    string
+		ConfigFile  string
+		Directory   string
+		Domains     string
+		SARIFOutput string
 	}
 }
 
@@ -80,11 +87,15 @@ func main() {
 	trackCommand.BoolVar(&help2, "help", false, "Show the program usage message")
 	trackCommand.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
 	trackCommand.StringVar(&args.Since, "since", "", "Exclude all assets discovered before (format: "+timeFormat+")")
+	trackCommand.BoolVar(&args.Options.DemoMode, "demo", false, "Replace the names with pseudonyms suitable for demonstrations")
+	trackCommand.StringVar(&args.Options.DemoKey, "demokey", "", "Key used to derive the -demo pseudonyms, keeping them consistent across runs and tools")
+	trackCommand.StringVar(&args.Options.Sort, "sort", requests.SortByName, "Order of the new names: name or rdns")
 	trackCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
 	trackCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
 	trackCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file")
 	trackCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
 	trackCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing registered domain names")
+	trackCommand.StringVar(&args.Filepaths.SARIFOutput, "sarif", "", "Path to the SARIF 2.1.0 output file containing the new names")
 
 	var usage = func() {
 		g.Fprintf(color.Error, "Usage: %s %s\n\n", path.Base(os.Args[0]), usageMsg)
@@ -111,6 +122,10 @@ func main() {
 		color.Output = io.Discard
 		color.Error = io.Discard
 	}
+	if args.Options.Sort != requests.SortByName && args.Options.Sort != requests.SortByRDNS {
+		r.Fprintf(color.Error, "%s is not a valid order for the names\n", args.Options.Sort)
+		os.Exit(1)
+	}
 	if args.Filepaths.Domains != "" {
 		list, err := config.GetListFromFile(args.Filepaths.Domains)
 		if err != nil {
@@ -154,9 +169,39 @@ func main() {
 		os.Exit(1)
 	}
 
-	for _, name := range getNewNames(args.Domains.Slice(), start, db) {
-		g.Fprintln(color.Output, name)
+	var rd *redact.Redactor
+	if args.Options.DemoMode {
+		rd = redact.NewRedactor([]byte(args.Options.DemoKey))
+	}
+
+	names := getNewNames(args.Domains.Slice(), start, db)
+	requests.SortNames(names, args.Options.Sort)
+	for i, name := range names {
+		names[i] = rd.Domain(name)
+		g.Fprintln(color.Output, names[i])
+	}
+	if args.Filepaths.SARIFOutput != "" {
+		if err := writeSARIFFile(args.Filepaths.SARIFOutput, names); err != nil {
+			r.Fprintf(color.Error, "Failed to write the SARIF output file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// writeSARIFFile reports each new name as a result of the new asset rule.
+func writeSARIFFile(path string, names []string) error {
+	var results []*findings.Finding
+	for _, name := range names {
+		results = append(results, findings.NewFinding(findings.NewAssetRule, string(oam.FQDN), name, nil))
 	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return findings.WriteSARIF(f, "oam_track", []*findings.Rule{findings.NewAssetRule}, results)
 }
 
 func getNewNames(domains []string, since time.Time, g *graph.Graph) []string {
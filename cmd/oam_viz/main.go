@@ -36,12 +36,14 @@ import (
 	"github.com/fatih/color"
 	"github.com/owasp-amass/config/config"
 	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/redact"
 	"github.com/owasp-amass/oam-tools/viz"
+	oam "github.com/owasp-amass/open-asset-model"
 )
 
 const (
 	timeFormat = "01/02 15:04:05 2006 MST"
-	usageMsg   = "-d3|-dot|-gexf [options] -d domain"
+	usageMsg   = "-d3|-dot|-gexf|-stix|-metrics [options] -d domain|-asset Type:key"
 )
 
 var (
@@ -52,13 +54,19 @@ var (
 
 type vizArgs struct {
 	Domains *stringset.Set
+	Asset   string
+	Radius  int
 	Since   string
 	Options struct {
-		D3      bool
-		DOT     bool
-		GEXF    bool
-		NoColor bool
-		Silent  bool
+		D3       bool
+		DemoMode bool
+		DemoKey  string
+		DOT      bool
+		GEXF     bool
+		STIX     bool
+		Metrics  bool
+		NoColor  bool
+		Silent   bool
 	}
 	Filepaths struct {
 		ConfigFile    string
@@ -83,6 +91,8 @@ func main() {
 	vizCommand.BoolVar(&help1, "h", false, "Show the program usage message")
 	vizCommand.BoolVar(&help2, "help", false, "Show the program usage message")
 	vizCommand.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
+	vizCommand.StringVar(&args.Asset, "asset", "", "Start from an asset instead of the domains (format: Type:key, e.g. IPAddress:1.2.3.4)")
+	vizCommand.IntVar(&args.Radius, "radius", 2, "Number of relations to follow from the -asset when building the graph")
 	vizCommand.StringVar(&args.Since, "since", "", "Include only assets validated after (format: "+timeFormat+")")
 	vizCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the YAML configuration file")
 	vizCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
@@ -90,8 +100,12 @@ func main() {
 	vizCommand.StringVar(&args.Filepaths.Output, "o", "", "Path to the directory for output files being generated")
 	vizCommand.StringVar(&args.Filepaths.AllFilePrefix, "oA", "", "Path prefix used for naming all output files")
 	vizCommand.BoolVar(&args.Options.D3, "d3", false, "Generate the D3 v4 force simulation HTML file")
+	vizCommand.BoolVar(&args.Options.DemoMode, "demo", false, "Replace names, addresses, ASNs and organizations with pseudonyms to share the graph files")
+	vizCommand.StringVar(&args.Options.DemoKey, "demokey", "", "Key used to derive the -demo pseudonyms, keeping them consistent across runs and tools")
 	vizCommand.BoolVar(&args.Options.DOT, "dot", false, "Generate the DOT output file")
 	vizCommand.BoolVar(&args.Options.GEXF, "gexf", false, "Generate the Gephi Graph Exchange XML Format (GEXF) file")
+	vizCommand.BoolVar(&args.Options.STIX, "stix", false, "Generate the STIX 2.1 bundle JSON file")
+	vizCommand.BoolVar(&args.Options.Metrics, "metrics", false, "Generate the ranked graph metrics text report")
 	vizCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
 	vizCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
 
@@ -128,17 +142,27 @@ func main() {
 		}
 		args.Domains.InsertMany(list...)
 	}
-	if args.Domains.Len() == 0 {
-		r.Fprintln(color.Error, "No root domain names were provided")
+	if args.Domains.Len() == 0 && args.Asset == "" {
+		r.Fprintln(color.Error, "No root domain names or asset were provided")
 		os.Exit(1)
 	}
 	// Make sure at least one graph file format has been identified on the command-line
-	if !args.Options.D3 && !args.Options.DOT && !args.Options.GEXF {
+	if !args.Options.D3 && !args.Options.DOT && !args.Options.GEXF &&
+		!args.Options.STIX && !args.Options.Metrics {
 		r.Fprintln(color.Error, "At least one file format must be selected")
 		os.Exit(1)
 	}
 
 	var err error
+	var asset oam.Asset
+	if args.Asset != "" {
+		asset, err = viz.ParseAsset(args.Asset)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to parse the asset: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	var start time.Time
 	if args.Since != "" {
 		start, err = time.Parse(timeFormat, args.Since)
@@ -168,7 +192,16 @@ func main() {
 		os.Exit(1)
 	}
 	// Obtain the visualization nodes & edges from the graph
-	nodes, edges := viz.VizData(args.Domains.Slice(), start, db)
+	var nodes []viz.Node
+	var edges []viz.Edge
+	if asset != nil {
+		nodes, edges = viz.EgoData(asset, args.Radius, start, db)
+	} else {
+		nodes, edges = viz.VizData(args.Domains.Slice(), start, db)
+	}
+	if args.Options.DemoMode {
+		nodes = viz.RedactNodes(nodes, redact.NewRedactor([]byte(args.Options.DemoKey)))
+	}
 	// Get the directory to save the files into
 	dir := args.Filepaths.Directory
 
@@ -197,6 +230,14 @@ func main() {
 		path := filepath.Join(dir, prefix+".gexf")
 		err = writeGraphOutputFile("gexf", path, nodes, edges)
 	}
+	if args.Options.STIX {
+		path := filepath.Join(dir, prefix+".json")
+		err = writeGraphOutputFile("stix", path, nodes, edges)
+	}
+	if args.Options.Metrics {
+		path := filepath.Join(dir, prefix+"_metrics.txt")
+		err = writeGraphOutputFile("metrics", path, nodes, edges)
+	}
 	if err != nil {
 		r.Fprintf(color.Error, "Failed to write the output file: %v\n", err)
 		os.Exit(1)
@@ -247,6 +288,10 @@ func writeGraphOutputFile(t string, path string, nodes []viz.Node, edges []viz.E
 		err = viz.WriteDOTData(f, nodes, edges)
 	case "gexf":
 		err = viz.WriteGEXFData(f, nodes, edges)
+	case "stix":
+		err = viz.WriteSTIXData(f, nodes, edges)
+	case "metrics":
+		err = viz.WriteMetricsReport(f, nodes, edges, 0)
 	}
 	return err
 }
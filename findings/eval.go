@@ -0,0 +1,371 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package findings
+
+import (
+	"net/netip"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	"github.com/owasp-amass/oam-tools/viz"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+)
+
+// The maximum number of CNAME records followed when resolving a name
+const maxCNAMEChain = 10
+
+// Finding is an asset matched by a rule.
+type Finding struct {
+	RuleID   string `json:"rule_id"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	// AssetType is the OAM type of the asset, such as FQDN, NetworkEndpoint or TLSCertificate
+	AssetType string `json:"asset_type"`
+	// Asset is the key identifying the asset within its type
+	Asset    string   `json:"asset"`
+	Evidence []string `json:"evidence,omitempty"`
+}
+
+type resolvedAddr struct {
+	asset *types.Asset
+	addr  netip.Addr
+}
+
+type endpointInfo struct {
+	atype    string
+	key      string
+	host     string
+	port     int
+	protocol string
+}
+
+// scopeData holds the assets within the scope, gathered once for all the rules.
+type scopeData struct {
+	g         *graph.Graph
+	since     time.Time
+	names     []string
+	addrs     map[string][]*resolvedAddr
+	asns      map[string][]int
+	endpoints []*endpointInfo
+	certs     []*viz.CertificateInfo
+}
+
+// Evaluate returns the findings of the rules for the assets within the scope of the domains,
+// ordered from the most to the least severe. The validity of certificates is checked against now.
+func Evaluate(rs *RuleSet, domains []string, since, now time.Time, g *graph.Graph) []*Finding {
+	results := []*Finding{}
+	if rs == nil || len(rs.Rules) == 0 || len(domains) == 0 {
+		return results
+	}
+
+	if !since.IsZero() {
+		since = since.UTC()
+	}
+
+	s := collectScope(g, domains, since, now)
+	corporate := make(map[int]struct{})
+	for _, asn := range rs.Corporate.ASNs {
+		corporate[asn] = struct{}{}
+	}
+
+	for _, rule := range rs.Rules {
+		switch rule.Asset {
+		case AssetFQDN:
+			if rule.Where.OutsideCorporateASNs && len(corporate) == 0 {
+				continue
+			}
+			for _, name := range s.names {
+				if evidence, ok := s.matchName(rule, name, corporate); ok {
+					results = append(results, NewFinding(rule, string(oam.FQDN), name, evidence))
+				}
+			}
+		case AssetEndpoint:
+			for _, ep := range s.endpoints {
+				if evidence, ok := matchEndpoint(rule, ep); ok {
+					results = append(results, NewFinding(rule, ep.atype, ep.key, evidence))
+				}
+			}
+		case AssetCertificate:
+			for _, c := range s.certs {
+				if evidence, ok := matchCertificate(rule, c, now); ok {
+					results = append(results, NewFinding(rule, string(oam.TLSCertificate), c.SerialNumber, evidence))
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		ri, rj := SeverityRank(results[i].Severity), SeverityRank(results[j].Severity)
+
+		if ri != rj {
+			return ri > rj
+		}
+		if results[i].RuleID != results[j].RuleID {
+			return results[i].RuleID < results[j].RuleID
+		}
+		return results[i].Asset < results[j].Asset
+	})
+	return results
+}
+
+// UsesCorporateASNs returns true when a rule of the set depends on the corporate ASNs.
+func (rs *RuleSet) UsesCorporateASNs() bool {
+	for _, rule := range rs.Rules {
+		if rule.Asset == AssetFQDN && rule.Where.OutsideCorporateASNs {
+			return true
+		}
+	}
+	return false
+}
+
+// NewFinding returns the finding of the rule for the asset identified by its type and key.
+func NewFinding(rule *Rule, atype, key string, evidence []string) *Finding {
+	return &Finding{
+		RuleID:    rule.ID,
+		Rule:      rule.Name,
+		Severity:  rule.Severity,
+		AssetType: atype,
+		Asset:     key,
+		Evidence:  evidence,
+	}
+}
+
+func (s *scopeData) matchName(rule *Rule, name string, corporate map[int]struct{}) ([]string, bool) {
+	var evidence []string
+
+	if rule.Where.Name != "" && !nameMatch(rule.Where.Name, name) {
+		return nil, false
+	}
+	if rule.Where.ResolvesReserved {
+		var found bool
+
+		for _, ra := range s.addrs[name] {
+			if reserved, cidr := requests.IsReservedAddress(ra.addr.String()); reserved {
+				found = true
+				evidence = append(evidence, "resolves to "+ra.addr.String()+" in the reserved range "+cidr)
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	if rule.Where.OutsideCorporateASNs {
+		var found bool
+
+		for _, ra := range s.addrs[name] {
+			for _, asn := range s.announcedBy(ra) {
+				if _, ok := corporate[asn]; !ok {
+					found = true
+					evidence = append(evidence, "resolves to "+ra.addr.String()+" announced by AS"+strconv.Itoa(asn))
+				}
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return evidence, true
+}
+
+func matchEndpoint(rule *Rule, ep *endpointInfo) ([]string, bool) {
+	var evidence []string
+
+	if rule.Where.Name != "" && !nameMatch(rule.Where.Name, ep.host) {
+		return nil, false
+	}
+	if len(rule.Where.Ports) > 0 {
+		var found bool
+
+		for _, p := range rule.Where.Ports {
+			if p == ep.port {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+		evidence = append(evidence, "port "+strconv.Itoa(ep.port)+" is reachable")
+	}
+	if rule.Where.Protocol != "" {
+		if !strings.EqualFold(rule.Where.Protocol, ep.protocol) {
+			return nil, false
+		}
+		evidence = append(evidence, "the "+ep.protocol+" protocol is offered")
+	}
+	return evidence, true
+}
+
+func matchCertificate(rule *Rule, c *viz.CertificateInfo, now time.Time) ([]string, bool) {
+	var evidence []string
+
+	if rule.Where.Name != "" {
+		var found bool
+
+		for _, n := range append([]string{c.Subject}, c.SANs...) {
+			if nameMatch(rule.Where.Name, n) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	if rule.Where.Expired || rule.Where.ExpiresWithinDays > 0 {
+		window := time.Duration(rule.Where.ExpiresWithinDays) * 24 * time.Hour
+		expired, expiring := viz.ExpiryStatus(c.NotAfter, now, window)
+
+		if rule.Where.Expired && !expired {
+			return nil, false
+		}
+		if rule.Where.ExpiresWithinDays > 0 && !expiring {
+			return nil, false
+		}
+
+		verb := "expires"
+		if expired {
+			verb = "expired"
+		}
+		evidence = append(evidence, verb+" on "+c.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if rule.Where.SelfSigned {
+		if !c.SelfSigned {
+			return nil, false
+		}
+		evidence = append(evidence, strings.TrimSpace("issued by its own subject "+c.Subject))
+	}
+	return evidence, true
+}
+
+func nameMatch(pattern, name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+	matched, err := path.Match(strings.ToLower(pattern), name)
+	return err == nil && matched
+}
+
+func collectScope(g *graph.Graph, domains []string, since, now time.Time) *scopeData {
+	s := &scopeData{
+		g:     g,
+		since: since,
+		addrs: make(map[string][]*resolvedAddr),
+		asns:  make(map[string][]int),
+	}
+
+	var fqdns []oam.Asset
+	for _, d := range domains {
+		fqdns = append(fqdns, &domain.FQDN{Name: d})
+	}
+
+	inscope := make(map[string]struct{})
+	if assets, err := g.DB.FindByScope(fqdns, since); err == nil {
+		for _, a := range assets {
+			n, ok := a.Asset.(*domain.FQDN)
+			if !ok || !requests.DomainNameInScope(n.Name, domains) {
+				continue
+			}
+			if _, found := s.addrs[n.Name]; found {
+				continue
+			}
+
+			s.names = append(s.names, n.Name)
+			s.addrs[n.Name] = s.resolve(a)
+			for _, ra := range s.addrs[n.Name] {
+				inscope[ra.addr.String()] = struct{}{}
+			}
+		}
+	}
+	sort.Strings(s.names)
+
+	if assets, err := g.DB.FindByType(oam.NetworkEndpoint, since); err == nil {
+		for _, a := range assets {
+			if ep, ok := a.Asset.(*domain.NetworkEndpoint); ok && requests.DomainNameInScope(ep.Name, domains) {
+				s.endpoints = append(s.endpoints, &endpointInfo{
+					atype:    string(oam.NetworkEndpoint),
+					key:      ep.Address,
+					host:     ep.Name,
+					port:     ep.Port,
+					protocol: ep.Protocol,
+				})
+			}
+		}
+	}
+	if assets, err := g.DB.FindByType(oam.SocketAddress, since); err == nil {
+		for _, a := range assets {
+			sa, ok := a.Asset.(*network.SocketAddress)
+			if !ok {
+				continue
+			}
+
+			if _, found := inscope[sa.IPAddress.String()]; found {
+				s.endpoints = append(s.endpoints, &endpointInfo{
+					atype:    string(oam.SocketAddress),
+					key:      sa.Address.String(),
+					host:     sa.IPAddress.String(),
+					port:     sa.Port,
+					protocol: sa.Protocol,
+				})
+			}
+		}
+	}
+
+	// The expiration windows are provided by the rules
+	s.certs = viz.CertificateInventory(g, domains, since, now, 0)
+	return s
+}
+
+// resolve returns the addresses of the name, following its CNAME records.
+func (s *scopeData) resolve(a *types.Asset) []*resolvedAddr {
+	var results []*resolvedAddr
+
+	seen := make(map[string]struct{})
+	for i := 0; a != nil && i <= maxCNAMEChain; i++ {
+		if _, found := seen[a.ID]; found {
+			break
+		}
+		seen[a.ID] = struct{}{}
+
+		for _, to := range requests.OutgoingAssets(s.g, a, s.since, "a_record", "aaaa_record") {
+			if ip, ok := to.Asset.(*network.IPAddress); ok {
+				results = append(results, &resolvedAddr{asset: to, addr: ip.Address})
+			}
+		}
+
+		var next *types.Asset
+		if targets := requests.OutgoingAssets(s.g, a, s.since, "cname_record"); len(targets) > 0 {
+			next = targets[0]
+		}
+		a = next
+	}
+	return results
+}
+
+// announcedBy returns the autonomous systems announcing a netblock that contains the address.
+func (s *scopeData) announcedBy(ra *resolvedAddr) []int {
+	if asns, found := s.asns[ra.asset.ID]; found {
+		return asns
+	}
+
+	var asns []int
+	for _, nb := range requests.IncomingAssets(s.g, ra.asset, s.since, "contains") {
+		for _, as := range requests.IncomingAssets(s.g, nb, s.since, "announces") {
+			if v, ok := as.Asset.(*network.AutonomousSystem); ok {
+				asns = append(asns, v.Number)
+			}
+		}
+	}
+
+	s.asns[ra.asset.ID] = asns
+	return asns
+}
@@ -0,0 +1,127 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package findings
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/graph"
+	oamcert "github.com/owasp-amass/open-asset-model/certificate"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// An administrative host announced by a hosting provider, and a name resolving to a private address
+	_, err := g.UpsertA(ctx, "www.findingstest.domain", "93.184.216.80")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "admin-portal.findingstest.domain", "93.184.216.81")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(ctx, "intranet.findingstest.domain", "lb.findingstest.domain")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "lb.findingstest.domain", "10.20.30.41")
+	assert.Nil(t, err)
+
+	nb, err := g.DB.Create(nil, "", &network.Netblock{CIDR: netip.MustParsePrefix("93.184.216.0/24"), Type: "IPv4"})
+	assert.Nil(t, err)
+	as, err := g.DB.Create(nil, "", &network.AutonomousSystem{Number: 64999})
+	assert.Nil(t, err)
+	_, err = g.DB.Link(as, "announces", nb)
+	assert.Nil(t, err)
+	for _, addr := range []string{"93.184.216.80", "93.184.216.81"} {
+		ips, err := g.DB.FindByContent(&network.IPAddress{Address: netip.MustParseAddr(addr), Type: "IPv4"}, time.Time{})
+		if assert.Nil(t, err) && assert.NotEmpty(t, ips) {
+			_, err = g.DB.Link(nb, "contains", ips[0])
+			assert.Nil(t, err)
+		}
+	}
+
+	// A Remote Desktop endpoint, and a database listening on an address within the scope
+	www, err := g.DB.FindByContent(&domain.FQDN{Name: "www.findingstest.domain"}, time.Time{})
+	if assert.Nil(t, err) && assert.NotEmpty(t, www) {
+		_, err = g.DB.Create(www[0], "port", &domain.NetworkEndpoint{
+			Address: "www.findingstest.domain:3389", Name: "www.findingstest.domain", Port: 3389, Protocol: "rdp"})
+		assert.Nil(t, err)
+	}
+	ip := netip.MustParseAddr("93.184.216.80")
+	_, err = g.DB.Create(nil, "", &network.SocketAddress{
+		Address: netip.AddrPortFrom(ip, 5432), IPAddress: ip, Port: 5432, Protocol: "postgresql"})
+	assert.Nil(t, err)
+	other := netip.MustParseAddr("93.184.216.99")
+	_, err = g.DB.Create(nil, "", &network.SocketAddress{
+		Address: netip.AddrPortFrom(other, 3306), IPAddress: other, Port: 3306, Protocol: "mysql"})
+	assert.Nil(t, err)
+
+	// An expired certificate, a self-signed certificate that expires soon, and one outside the scope
+	certs := []*oamcert.TLSCertificate{
+		{SerialNumber: "findingstest01", SubjectCommonName: "www.findingstest.domain", IssuerCommonName: "Findingstest CA",
+			NotAfter: now.Add(-24 * time.Hour).Format(time.RFC3339), AuthorityKeyID: "ca", SubjectKeyID: "leaf"},
+		{SerialNumber: "findingstest02", SubjectCommonName: "*.findingstest.domain", IssuerCommonName: "*.findingstest.domain",
+			NotAfter: now.Add(7 * 24 * time.Hour).Format(time.RFC3339)},
+		{SerialNumber: "findingstest03", SubjectCommonName: "www.findingstest-other.com", IssuerCommonName: "www.findingstest-other.com",
+			NotAfter: now.Add(-24 * time.Hour).Format(time.RFC3339)},
+	}
+	for _, c := range certs {
+		_, err = g.DB.Create(nil, "", c)
+		assert.Nil(t, err)
+	}
+
+	root, err := g.DB.Create(nil, "", &domain.FQDN{Name: "findingstest.domain"})
+	assert.Nil(t, err)
+	for _, sub := range []string{"www", "admin-portal", "intranet"} {
+		assets, err := g.DB.FindByContent(&domain.FQDN{Name: sub + ".findingstest.domain"}, time.Time{})
+		if assert.Nil(t, err) && assert.NotEmpty(t, assets) {
+			_, err = g.DB.Link(root, "node", assets[0])
+			assert.Nil(t, err)
+		}
+	}
+
+	rs, err := DefaultRules()
+	assert.Nil(t, err)
+
+	// Without corporate ASNs, the rules depending on them are skipped
+	results := Evaluate(rs, []string{"findingstest.domain"}, time.Time{}, now, g)
+	expected := []string{
+		"database-exposed|SocketAddress|93.184.216.80:5432",
+		"rdp-exposed|NetworkEndpoint|www.findingstest.domain:3389",
+		"certificate-expired|TLSCertificate|findingstest01",
+		"reserved-address-in-public-dns|FQDN|intranet.findingstest.domain",
+		"certificate-expiring-soon|TLSCertificate|findingstest02",
+		"certificate-self-signed|TLSCertificate|findingstest02",
+	}
+	assert.Equal(t, expected, findingKeys(results))
+	assert.Equal(t, []string{"resolves to 10.20.30.41 in the reserved range 10.0.0.0/8"}, results[3].Evidence)
+
+	rs.Corporate.ASNs = []int{64500}
+	results = Evaluate(rs, []string{"findingstest.domain"}, time.Time{}, now, g)
+	expected = append([]string{"admin-host-outside-corporate-asn|FQDN|admin-portal.findingstest.domain"}, expected...)
+	assert.Equal(t, expected, findingKeys(results))
+	assert.Equal(t, SeverityHigh, results[0].Severity)
+	assert.Equal(t, []string{"resolves to 93.184.216.81 announced by AS64999"}, results[0].Evidence)
+
+	// Names hosted within the corporate networks are not reported
+	rs.Corporate.ASNs = []int{64999}
+	results = Evaluate(rs, []string{"findingstest.domain"}, time.Time{}, now, g)
+	assert.Len(t, results, len(expected)-1)
+
+	assert.Empty(t, Evaluate(rs, nil, time.Time{}, now, g))
+}
+
+func findingKeys(results []*Finding) []string {
+	var keys []string
+
+	for _, f := range results {
+		keys = append(keys, f.RuleID+"|"+f.AssetType+"|"+f.Asset)
+	}
+	return keys
+}
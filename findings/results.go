@@ -0,0 +1,42 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package findings
+
+// Rules describing the results of the other tools in their SARIF output. The identifiers and
+// metadata must remain unchanged, so that the results of repeated runs are deduplicated.
+var (
+	// NewAssetRule is reported by oam_track for each name discovered since the last enumeration
+	NewAssetRule = &Rule{
+		ID:          "new-asset",
+		Name:        "New asset discovered",
+		Description: "A name within the scope was discovered by the latest enumeration.",
+		Severity:    SeverityInfo,
+		Asset:       AssetFQDN,
+	}
+	// DanglingCNAMERule is reported by oam_subs for names whose CNAME target has no address records
+	DanglingCNAMERule = &Rule{
+		ID:          "dangling-cname",
+		Name:        "Dangling CNAME record",
+		Description: "A name within the scope is an alias for a name outside the scope that has no address records, which could be registered or claimed by someone else.",
+		Severity:    SeverityHigh,
+		Asset:       AssetFQDN,
+	}
+	// TakeoverProneServiceRule is reported by oam_subs for names aliased to takeover-prone services
+	TakeoverProneServiceRule = &Rule{
+		ID:          "takeover-prone-service",
+		Name:        "CNAME to a takeover-prone service",
+		Description: "A name within the scope is an alias for a hosted service that allows the resource to be claimed by another account once it is released.",
+		Severity:    SeverityMedium,
+		Asset:       AssetFQDN,
+	}
+)
+
+// Identifiers of the default rules on certificates, also reported by oam_inventory in its SARIF output.
+// The rules are looked up in the default rule pack, so both tools describe them the same way.
+const (
+	CertificateExpiredID      = "certificate-expired"
+	CertificateExpiringSoonID = "certificate-expiring-soon"
+	CertificateSelfSignedID   = "certificate-self-signed"
+)
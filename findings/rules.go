@@ -0,0 +1,234 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package findings evaluates rules against the assets within the scope of the graph
+// and reports the assets that match them, along with the severity of each rule.
+package findings
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severities that can be assigned to the rules, from the least to the most severe.
+const (
+	SeverityInfo     = "info"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Severities lists the supported severities, from the least to the most severe.
+var Severities = []string{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// The kinds of assets that rules can be evaluated against.
+const (
+	// AssetFQDN selects the names within the scope
+	AssetFQDN = "FQDN"
+	// AssetEndpoint selects the network endpoints and socket addresses within the scope
+	AssetEndpoint = "Endpoint"
+	// AssetCertificate selects the TLS certificates issued for names within the scope
+	AssetCertificate = "TLSCertificate"
+)
+
+//go:embed default_rules.yaml
+var defaultRules []byte
+
+// RuleSet is the content of a YAML rule file.
+type RuleSet struct {
+	Corporate struct {
+		// ASNs are the autonomous systems operated by the target, used by the outside_corporate_asns condition
+		ASNs []int `yaml:"asns" json:"asns"`
+	} `yaml:"corporate" json:"corporate"`
+	Rules []*Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule selects the assets of a kind that meet all of its conditions.
+type Rule struct {
+	ID          string     `yaml:"id" json:"id"`
+	Name        string     `yaml:"name" json:"name"`
+	Description string     `yaml:"description" json:"description"`
+	Severity    string     `yaml:"severity" json:"severity"`
+	Asset       string     `yaml:"asset" json:"asset"`
+	Where       Conditions `yaml:"where" json:"where"`
+}
+
+// Conditions are the checks performed by a rule. Only the conditions that are set are checked,
+// and each of them is supported by specific kinds of assets.
+type Conditions struct {
+	// Name is a glob pattern matched against the name, host or certificate names, ignoring case
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// ResolvesReserved matches names that resolve to reserved addresses
+	ResolvesReserved bool `yaml:"resolves_reserved,omitempty" json:"resolves_reserved,omitempty"`
+	// OutsideCorporateASNs matches names that resolve to addresses announced by other autonomous systems
+	OutsideCorporateASNs bool `yaml:"outside_corporate_asns,omitempty" json:"outside_corporate_asns,omitempty"`
+	// Ports matches endpoints on any of the ports
+	Ports []int `yaml:"ports,omitempty" json:"ports,omitempty"`
+	// Protocol matches endpoints with the protocol, ignoring case
+	Protocol string `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+	// Expired matches certificates that are no longer valid
+	Expired bool `yaml:"expired,omitempty" json:"expired,omitempty"`
+	// ExpiresWithinDays matches valid certificates that expire within the number of days
+	ExpiresWithinDays int `yaml:"expires_within_days,omitempty" json:"expires_within_days,omitempty"`
+	// SelfSigned matches certificates issued by their own subject
+	SelfSigned bool `yaml:"self_signed,omitempty" json:"self_signed,omitempty"`
+}
+
+// DefaultRules returns the rule pack distributed with the tools.
+func DefaultRules() (*RuleSet, error) {
+	return ParseRules(bytes.NewReader(defaultRules))
+}
+
+// LoadRules reads and merges the YAML rule files.
+func LoadRules(paths ...string) (*RuleSet, error) {
+	rs := new(RuleSet)
+
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+
+		set, err := ParseRules(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+		if err := rs.Merge(set); err != nil {
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+	}
+	return rs, nil
+}
+
+// ParseRules decodes and validates a YAML rule set. Unknown fields are rejected, so that
+// misspelled conditions are not silently ignored.
+func ParseRules(r io.Reader) (*RuleSet, error) {
+	rs := new(RuleSet)
+
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(rs); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	ids := make(map[string]struct{})
+	for _, rule := range rs.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+		if _, found := ids[rule.ID]; found {
+			return nil, fmt.Errorf("rule %s is defined more than once", rule.ID)
+		}
+		ids[rule.ID] = struct{}{}
+	}
+	return rs, nil
+}
+
+// Merge adds the corporate ASNs and rules of the other rule set. Rule identifiers must remain unique.
+func (rs *RuleSet) Merge(other *RuleSet) error {
+	ids := make(map[string]struct{})
+	for _, rule := range rs.Rules {
+		ids[rule.ID] = struct{}{}
+	}
+
+	for _, rule := range other.Rules {
+		if _, found := ids[rule.ID]; found {
+			return fmt.Errorf("rule %s is defined more than once", rule.ID)
+		}
+		ids[rule.ID] = struct{}{}
+	}
+
+	rs.Rules = append(rs.Rules, other.Rules...)
+	rs.Corporate.ASNs = append(rs.Corporate.ASNs, other.Corporate.ASNs...)
+	return nil
+}
+
+// Rule returns the rule with the identifier, or nil when the rule set does not contain it.
+func (rs *RuleSet) Rule(id string) *Rule {
+	for _, rule := range rs.Rules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	if r.ID == "" {
+		return errors.New("a rule is missing its id")
+	}
+	if SeverityRank(r.Severity) < 0 {
+		return fmt.Errorf("rule %s: %q is not a valid severity", r.ID, r.Severity)
+	}
+	r.Severity = strings.ToLower(r.Severity)
+	if r.Where.Name != "" {
+		if _, err := path.Match(r.Where.Name, ""); err != nil {
+			return fmt.Errorf("rule %s: %q is not a valid name pattern", r.ID, r.Where.Name)
+		}
+	}
+	for _, p := range r.Where.Ports {
+		if p < 0 || p > 65535 {
+			return fmt.Errorf("rule %s: %d is not a valid port number", r.ID, p)
+		}
+	}
+	if r.Where.ExpiresWithinDays < 0 {
+		return fmt.Errorf("rule %s: expires_within_days cannot be negative", r.ID)
+	}
+
+	w := r.Where
+	var supported, unsupported []bool
+	switch r.Asset {
+	case AssetFQDN:
+		supported = []bool{w.Name != "", w.ResolvesReserved, w.OutsideCorporateASNs}
+		unsupported = []bool{len(w.Ports) > 0, w.Protocol != "", w.Expired, w.ExpiresWithinDays > 0, w.SelfSigned}
+	case AssetEndpoint:
+		supported = []bool{w.Name != "", len(w.Ports) > 0, w.Protocol != ""}
+		unsupported = []bool{w.ResolvesReserved, w.OutsideCorporateASNs, w.Expired, w.ExpiresWithinDays > 0, w.SelfSigned}
+	case AssetCertificate:
+		supported = []bool{w.Name != "", w.Expired, w.ExpiresWithinDays > 0, w.SelfSigned}
+		unsupported = []bool{w.ResolvesReserved, w.OutsideCorporateASNs, len(w.Ports) > 0, w.Protocol != ""}
+	default:
+		return fmt.Errorf("rule %s: %q is not a supported asset, use %s, %s or %s",
+			r.ID, r.Asset, AssetFQDN, AssetEndpoint, AssetCertificate)
+	}
+
+	if anySet(unsupported) {
+		return fmt.Errorf("rule %s: a condition is not supported by the %s asset", r.ID, r.Asset)
+	}
+	if !anySet(supported) {
+		return fmt.Errorf("rule %s: at least one condition must be provided", r.ID)
+	}
+	return nil
+}
+
+func anySet(conds []bool) bool {
+	for _, c := range conds {
+		if c {
+			return true
+		}
+	}
+	return false
+}
+
+// SeverityRank returns the position of the severity within Severities, or -1 when it is not supported.
+func SeverityRank(severity string) int {
+	severity = strings.ToLower(severity)
+
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
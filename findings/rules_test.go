@@ -0,0 +1,116 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package findings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRules(t *testing.T) {
+	rs, err := DefaultRules()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, rs.Rules)
+	assert.Empty(t, rs.Corporate.ASNs)
+	assert.True(t, rs.UsesCorporateASNs())
+
+	for _, rule := range rs.Rules {
+		assert.NotEmpty(t, rule.Name, rule.ID)
+		assert.NotEmpty(t, rule.Description, rule.ID)
+		assert.GreaterOrEqual(t, SeverityRank(rule.Severity), 0, rule.ID)
+	}
+	assert.NotNil(t, rs.Rule("rdp-exposed"))
+	assert.Nil(t, rs.Rule("missing-rule"))
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{
+			name: "valid",
+			yaml: "corporate:\n  asns: [64500]\nrules:\n  - id: ssh\n    severity: Medium\n    asset: Endpoint\n    where:\n      ports: [22]\n",
+		},
+		{
+			name: "unknown condition",
+			yaml: "rules:\n  - id: ssh\n    severity: low\n    asset: Endpoint\n    where:\n      port: [22]\n",
+			err:  "port",
+		},
+		{
+			name: "invalid severity",
+			yaml: "rules:\n  - id: ssh\n    severity: urgent\n    asset: Endpoint\n    where:\n      ports: [22]\n",
+			err:  "not a valid severity",
+		},
+		{
+			name: "unsupported asset",
+			yaml: "rules:\n  - id: ssh\n    severity: low\n    asset: URL\n    where:\n      ports: [22]\n",
+			err:  "not a supported asset",
+		},
+		{
+			name: "unsupported condition",
+			yaml: "rules:\n  - id: ssh\n    severity: low\n    asset: FQDN\n    where:\n      ports: [22]\n",
+			err:  "not supported by the FQDN asset",
+		},
+		{
+			name: "missing conditions",
+			yaml: "rules:\n  - id: ssh\n    severity: low\n    asset: Endpoint\n",
+			err:  "at least one condition",
+		},
+		{
+			name: "invalid pattern",
+			yaml: "rules:\n  - id: admin\n    severity: low\n    asset: FQDN\n    where:\n      name: \"[admin\"\n",
+			err:  "not a valid name pattern",
+		},
+		{
+			name: "duplicate",
+			yaml: "rules:\n  - id: ssh\n    severity: low\n    asset: Endpoint\n    where:\n      ports: [22]\n  - id: ssh\n    severity: low\n    asset: Endpoint\n    where:\n      ports: [2222]\n",
+			err:  "more than once",
+		},
+	}
+
+	for _, test := range tests {
+		rs, err := ParseRules(strings.NewReader(test.yaml))
+
+		if test.err == "" {
+			if assert.Nil(t, err, test.name) {
+				assert.Equal(t, []int{64500}, rs.Corporate.ASNs)
+				assert.Equal(t, SeverityMedium, rs.Rules[0].Severity)
+			}
+			continue
+		}
+		if assert.NotNil(t, err, test.name) {
+			assert.Contains(t, err.Error(), test.err, test.name)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	second := filepath.Join(dir, "second.yaml")
+
+	assert.Nil(t, os.WriteFile(first, []byte("corporate:\n  asns: [64500]\nrules:\n  - id: ssh\n    severity: low\n    asset: Endpoint\n    where:\n      ports: [22]\n"), 0644))
+	assert.Nil(t, os.WriteFile(second, []byte("corporate:\n  asns: [64501]\nrules:\n  - id: vpn\n    severity: info\n    asset: FQDN\n    where:\n      name: \"vpn*\"\n"), 0644))
+
+	rs, err := LoadRules(first, second)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{64500, 64501}, rs.Corporate.ASNs)
+	assert.Len(t, rs.Rules, 2)
+
+	// The identifiers must remain unique across the files
+	_, err = LoadRules(first, first)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "more than once")
+	}
+
+	_, err = LoadRules(filepath.Join(dir, "missing.yaml"))
+	assert.NotNil(t, err)
+}
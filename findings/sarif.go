@@ -0,0 +1,198 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package findings
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolURI      = "https://github.com/owasp-amass/oam-tools"
+	// The key of the partial fingerprint identifying a result across runs
+	fingerprintKey = "oamAsset/v1"
+)
+
+// The SARIF levels and security severity scores of each severity
+var sarifSeverities = map[string]struct {
+	level string
+	score string
+}{
+	SeverityInfo:     {level: "note", score: "0.0"},
+	SeverityLow:      {level: "note", score: "3.0"},
+	SeverityMedium:   {level: "warning", score: "5.5"},
+	SeverityHigh:     {level: "error", score: "8.0"},
+	SeverityCritical: {level: "error", score: "9.5"},
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string          `json:"id"`
+	Name                 string          `json:"name"`
+	ShortDescription     sarifMessage    `json:"shortDescription"`
+	FullDescription      *sarifMessage   `json:"fullDescription,omitempty"`
+	DefaultConfiguration sarifRuleConfig `json:"defaultConfiguration"`
+	Properties           sarifRuleProps  `json:"properties"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProps struct {
+	SecuritySeverity string   `json:"security-severity"`
+	Tags             []string `json:"tags"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log produced by the tool. Every rule is described
+// in the log, even without results, and each finding is reported at the logical location of its asset.
+// The partial fingerprints only depend on the rule and the asset, so results can be tracked across runs.
+func WriteSARIF(w io.Writer, tool string, rules []*Rule, findings []*Finding) error {
+	driver := sarifDriver{
+		Name:           tool,
+		InformationURI: toolURI,
+		Rules:          []sarifRule{},
+	}
+
+	index := make(map[string]int)
+	for _, rule := range rules {
+		if _, found := index[rule.ID]; found {
+			continue
+		}
+		index[rule.ID] = len(driver.Rules)
+
+		sr := sarifRule{
+			ID:                   rule.ID,
+			Name:                 sarifRuleName(rule.ID),
+			ShortDescription:     sarifMessage{Text: rule.Name},
+			DefaultConfiguration: sarifRuleConfig{Level: sarifSeverities[rule.Severity].level},
+			Properties: sarifRuleProps{
+				SecuritySeverity: sarifSeverities[rule.Severity].score,
+				Tags:             []string{"security", rule.Asset},
+			},
+		}
+		if sr.ShortDescription.Text == "" {
+			sr.ShortDescription.Text = rule.ID
+		}
+		if rule.Description != "" {
+			sr.FullDescription = &sarifMessage{Text: rule.Description}
+		}
+		driver.Rules = append(driver.Rules, sr)
+	}
+
+	results := []sarifResult{}
+	for _, f := range findings {
+		idx, found := index[f.RuleID]
+		if !found {
+			continue
+		}
+
+		text := f.Rule + ": " + f.Asset
+		if f.Rule == "" {
+			text = f.RuleID + ": " + f.Asset
+		}
+		if len(f.Evidence) > 0 {
+			text += " (" + strings.Join(f.Evidence, "; ") + ")"
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    f.RuleID,
+			RuleIndex: idx,
+			Level:     sarifSeverities[f.Severity].level,
+			Message:   sarifMessage{Text: text},
+			Locations: []sarifLocation{{
+				LogicalLocations: []sarifLogicalLocation{{
+					Name:               f.Asset,
+					FullyQualifiedName: f.AssetType + ":" + f.Asset,
+					Kind:               "resource",
+				}},
+			}},
+			PartialFingerprints: map[string]string{fingerprintKey: Fingerprint(f)},
+			Properties: map[string]any{
+				"assetType": f.AssetType,
+				"severity":  f.Severity,
+			},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(&sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: driver},
+			Results: results,
+		}},
+	})
+}
+
+// Fingerprint returns the stable identifier of the finding, derived from the rule and the asset.
+func Fingerprint(f *Finding) string {
+	sum := sha256.Sum256([]byte(f.RuleID + "\x00" + f.AssetType + "\x00" + f.Asset))
+	return hex.EncodeToString(sum[:16])
+}
+
+// sarifRuleName converts the rule identifier to the PascalCase name expected by SARIF consumers.
+func sarifRuleName(id string) string {
+	var name strings.Builder
+
+	for _, part := range strings.FieldsFunc(id, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' '
+	}) {
+		name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return name.String()
+}
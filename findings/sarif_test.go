@@ -0,0 +1,90 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package findings
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	rs, err := DefaultRules()
+	assert.Nil(t, err)
+
+	results := []*Finding{
+		NewFinding(rs.Rule("rdp-exposed"), "NetworkEndpoint", "www.owasp.org:3389", []string{"port 3389 is reachable"}),
+		NewFinding(rs.Rule("certificate-self-signed"), "TLSCertificate", "1234", nil),
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteSARIF(&buf, "oam_findings", rs.Rules, results))
+
+	var log sarifLog
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	if !assert.Len(t, log.Runs, 1) {
+		return
+	}
+
+	run := log.Runs[0]
+	assert.Equal(t, "oam_findings", run.Tool.Driver.Name)
+	assert.Len(t, run.Tool.Driver.Rules, len(rs.Rules))
+	assert.Len(t, run.Results, 2)
+
+	rdp := run.Results[0]
+	assert.Equal(t, "rdp-exposed", rdp.RuleID)
+	assert.Equal(t, "rdp-exposed", run.Tool.Driver.Rules[rdp.RuleIndex].ID)
+	assert.Equal(t, "RdpExposed", run.Tool.Driver.Rules[rdp.RuleIndex].Name)
+	assert.Equal(t, "8.0", run.Tool.Driver.Rules[rdp.RuleIndex].Properties.SecuritySeverity)
+	assert.Equal(t, "error", rdp.Level)
+	assert.Equal(t, "Remote Desktop exposed: www.owasp.org:3389 (port 3389 is reachable)", rdp.Message.Text)
+	assert.Equal(t, "NetworkEndpoint:www.owasp.org:3389", rdp.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Equal(t, "note", run.Results[1].Level)
+
+	// The fingerprints are stable across runs and differ between the assets
+	assert.Equal(t, Fingerprint(results[0]), rdp.PartialFingerprints[fingerprintKey])
+	assert.NotEqual(t, rdp.PartialFingerprints[fingerprintKey], run.Results[1].PartialFingerprints[fingerprintKey])
+
+	// The rules are described even when nothing was found
+	buf.Reset()
+	assert.Nil(t, WriteSARIF(&buf, "oam_findings", rs.Rules, nil))
+	assert.Contains(t, buf.String(), `"results": []`)
+}
+
+func TestResultRules(t *testing.T) {
+	rules := []*Rule{NewAssetRule, DanglingCNAMERule, TakeoverProneServiceRule}
+
+	ids := make(map[string]struct{})
+	for _, rule := range rules {
+		_, found := ids[rule.ID]
+		assert.False(t, found, rule.ID)
+		ids[rule.ID] = struct{}{}
+
+		assert.NotEmpty(t, rule.Name, rule.ID)
+		assert.NotEmpty(t, rule.Description, rule.ID)
+		assert.GreaterOrEqual(t, SeverityRank(rule.Severity), 0, rule.ID)
+	}
+
+	// The certificate rules reported by other tools are part of the default rule pack
+	rs, err := DefaultRules()
+	assert.Nil(t, err)
+	for _, id := range []string{CertificateExpiredID, CertificateExpiringSoonID, CertificateSelfSignedID} {
+		rule := rs.Rule(id)
+		if assert.NotNil(t, rule, id) {
+			assert.Equal(t, AssetCertificate, rule.Asset, id)
+			_, found := ids[id]
+			assert.False(t, found, id)
+		}
+	}
+
+	// Repeated runs reporting the same asset produce the same fingerprint
+	first := NewFinding(NewAssetRule, "FQDN", "www.owasp.org", nil)
+	second := NewFinding(NewAssetRule, "FQDN", "www.owasp.org", []string{"other evidence"})
+	assert.Equal(t, Fingerprint(first), Fingerprint(second))
+	assert.NotEqual(t, Fingerprint(first), Fingerprint(NewFinding(DanglingCNAMERule, "FQDN", "www.owasp.org", nil)))
+}
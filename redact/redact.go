@@ -0,0 +1,230 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redact replaces the identifying details in the tool outputs with keyed pseudonyms,
+// so that results and graphs can be shared without revealing the target.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// The private use range of 32-bit ASNs that pseudonyms are mapped into (RFC 6996)
+const (
+	privateASNStart = 4200000000
+	privateASNCount = 94967295
+)
+
+// The number of characters kept from the keyed hash when building a pseudonym
+const tokenLen = 8
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Redactor replaces domain names, addresses, ASNs, organizations and other details with
+// pseudonyms derived from a secret key. The same input and key always produce the same pseudonym,
+// so distinct hosts remain distinct and outputs from separate runs can be compared. A nil
+// Redactor returns every value unchanged.
+type Redactor struct {
+	mu    sync.Mutex
+	key   []byte
+	cache map[string]string
+}
+
+// NewRedactor returns a Redactor deriving the pseudonyms from the key.
+// A random key is generated when the key is empty, keeping the pseudonyms consistent within a single run.
+func NewRedactor(key []byte) *Redactor {
+	if len(key) == 0 {
+		key = make([]byte, sha256.Size)
+		_, _ = rand.Read(key)
+	}
+
+	return &Redactor{
+		key:   key,
+		cache: make(map[string]string),
+	}
+}
+
+// Domain returns the pseudonym of the FQDN. Each label is replaced on its own, so the hierarchy of the
+// names is preserved, while the public suffix and wildcard labels are kept.
+func (r *Redactor) Domain(name string) string {
+	if r == nil {
+		return name
+	}
+
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	if name == "" {
+		return name
+	}
+
+	suffix, _ := publicsuffix.PublicSuffix(name)
+	if name == suffix {
+		return name
+	}
+
+	labels := strings.Split(strings.TrimSuffix(name, "."+suffix), ".")
+	for i, label := range labels {
+		if label != "*" && label != "" {
+			labels[i] = r.Label(label)
+		}
+	}
+	return strings.Join(append(labels, suffix), ".")
+}
+
+// Label returns the pseudonym of a single DNS label.
+func (r *Redactor) Label(label string) string {
+	if r == nil {
+		return label
+	}
+	return r.token("label", strings.ToLower(label))
+}
+
+// Addr returns the pseudonym of the IP address. The mapping is prefix-preserving: addresses sharing
+// the first n bits produce pseudonyms that share their first n bits, which keeps netblocks intact.
+func (r *Redactor) Addr(addr netip.Addr) netip.Addr {
+	if r == nil || !addr.IsValid() {
+		return addr
+	}
+	addr = addr.Unmap()
+
+	key := "addr:" + addr.String()
+	r.mu.Lock()
+	cached, found := r.cache[key]
+	r.mu.Unlock()
+	if found {
+		return netip.MustParseAddr(cached)
+	}
+
+	in := addr.AsSlice()
+	out := make([]byte, len(in))
+	prefix := make([]byte, len(in))
+	for i := 0; i < len(in)*8; i++ {
+		byteIdx, mask := i/8, byte(0x80>>(i%8))
+
+		// The bit is flipped based on the bits preceding it in the original address
+		flip := r.sum("addr", strconv.Itoa(len(in)), strconv.Itoa(i), string(prefix))[0] & 0x80
+		if (in[byteIdx]&mask != 0) != (flip != 0) {
+			out[byteIdx] |= mask
+		}
+		prefix[byteIdx] |= in[byteIdx] & mask
+	}
+
+	result, _ := netip.AddrFromSlice(out)
+	r.mu.Lock()
+	r.cache[key] = result.String()
+	r.mu.Unlock()
+	return result
+}
+
+// IP returns the pseudonym of the textual IP address, or a keyed token when it cannot be parsed.
+func (r *Redactor) IP(addr string) string {
+	if r == nil {
+		return addr
+	}
+
+	ip, err := netip.ParseAddr(strings.TrimSpace(addr))
+	if err != nil {
+		return r.Value(addr)
+	}
+	return r.Addr(ip).String()
+}
+
+// Prefix returns the pseudonym of the netblock, which contains the pseudonyms of all its addresses.
+func (r *Redactor) Prefix(prefix netip.Prefix) netip.Prefix {
+	if r == nil || !prefix.IsValid() {
+		return prefix
+	}
+
+	prefix = prefix.Masked()
+	return netip.PrefixFrom(r.Addr(prefix.Addr()), prefix.Bits()).Masked()
+}
+
+// Netblock returns the pseudonym of the textual CIDR, or a keyed token when it cannot be parsed.
+func (r *Redactor) Netblock(cidr string) string {
+	if r == nil {
+		return cidr
+	}
+
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+	if err != nil {
+		return r.Value(cidr)
+	}
+	return r.Prefix(prefix).String()
+}
+
+// ASN returns a pseudonym from the private use range of 32-bit ASNs. Zero is returned unchanged,
+// since it represents an unknown autonomous system.
+func (r *Redactor) ASN(asn int) int {
+	if r == nil || asn == 0 {
+		return asn
+	}
+
+	sum := r.sum("asn", strconv.Itoa(asn))
+	n := uint64(sum[0])<<24 | uint64(sum[1])<<16 | uint64(sum[2])<<8 | uint64(sum[3])
+	return privateASNStart + int(n%privateASNCount)
+}
+
+// Organization returns the pseudonym of the organization name, ignoring case and surrounding whitespace.
+func (r *Redactor) Organization(name string) string {
+	if r == nil || strings.TrimSpace(name) == "" {
+		return name
+	}
+	return "org-" + r.token("org", strings.ToLower(strings.TrimSpace(name)))
+}
+
+// Email returns the email address with the mailbox replaced by a token and the domain by its pseudonym.
+func (r *Redactor) Email(addr string) string {
+	if r == nil {
+		return addr
+	}
+
+	mailbox, domain, found := strings.Cut(strings.ToLower(strings.TrimSpace(addr)), "@")
+	if !found {
+		return r.Value(addr)
+	}
+	return r.token("mailbox", mailbox) + "@" + r.Domain(domain)
+}
+
+// Value returns a keyed token for any other detail, such as a certificate serial number or phone number.
+func (r *Redactor) Value(v string) string {
+	if r == nil || v == "" {
+		return v
+	}
+	return r.token("value", v)
+}
+
+func (r *Redactor) token(kind, v string) string {
+	key := kind + ":" + v
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, found := r.cache[key]; found {
+		return t
+	}
+
+	t := strings.ToLower(encoding.EncodeToString(r.sum(kind, v)))[:tokenLen]
+	r.cache[key] = t
+	return t
+}
+
+// sum returns the keyed hash of the parts, which are separated to keep the inputs unambiguous.
+func (r *Redactor) sum(parts ...string) []byte {
+	mac := hmac.New(sha256.New, r.key)
+
+	for _, p := range parts {
+		mac.Write([]byte(strconv.Itoa(len(p))))
+		mac.Write([]byte{0})
+		mac.Write([]byte(p))
+	}
+	return mac.Sum(nil)
+}
@@ -0,0 +1,120 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package redact
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomain(t *testing.T) {
+	r := NewRedactor([]byte("test key"))
+
+	www := r.Domain("www.owasp.org")
+	assert.True(t, strings.HasSuffix(www, ".org"))
+	assert.Equal(t, 3, len(strings.Split(www, ".")))
+	assert.NotContains(t, www, "owasp")
+
+	// Pseudonyms are deterministic, ignore case and keep the hierarchy of the names
+	assert.Equal(t, www, r.Domain("WWW.OWASP.ORG."))
+	assert.Equal(t, www, NewRedactor([]byte("test key")).Domain("www.owasp.org"))
+	assert.True(t, strings.HasSuffix(www, "."+r.Domain("owasp.org")))
+	assert.True(t, strings.HasSuffix(r.Domain("api.owasp.co.uk"), ".co.uk"))
+	assert.NotEqual(t, www, r.Domain("api.owasp.org"))
+	assert.True(t, strings.HasPrefix(r.Domain("*.owasp.org"), "*."))
+	assert.Equal(t, "org", r.Domain("org"))
+
+	// A different key produces different pseudonyms
+	assert.NotEqual(t, www, NewRedactor([]byte("other key")).Domain("www.owasp.org"))
+}
+
+func TestAddr(t *testing.T) {
+	r := NewRedactor([]byte("test key"))
+
+	tests := []struct {
+		first, second string
+		shared        int
+	}{
+		{first: "192.0.2.10", second: "192.0.2.200", shared: 24},
+		{first: "198.51.100.1", second: "198.51.100.2", shared: 30},
+		{first: "2001:db8:1::1", second: "2001:db8:1::ffff", shared: 112},
+		{first: "2001:db8:1:2::1", second: "2001:db8:1:3::1", shared: 63},
+	}
+
+	for _, test := range tests {
+		first, second := netip.MustParseAddr(test.first), netip.MustParseAddr(test.second)
+		p1, p2 := r.Addr(first), r.Addr(second)
+
+		assert.NotEqual(t, first, p1)
+		assert.NotEqual(t, p1, p2)
+		assert.Equal(t, first.Is4(), p1.Is4())
+		assert.Equal(t, p1, r.Addr(first))
+		// The pseudonyms share exactly as many leading bits as the original addresses
+		assert.Equal(t, test.shared, commonBits(p1, p2), test.first+" and "+test.second)
+	}
+
+	assert.NotEqual(t, "not an address", r.IP("not an address"))
+}
+
+func TestNetblock(t *testing.T) {
+	r := NewRedactor([]byte("test key"))
+
+	for _, cidr := range []string{"192.0.2.0/24", "2001:db8::/32"} {
+		prefix := netip.MustParsePrefix(cidr)
+		pseudo := netip.MustParsePrefix(r.Netblock(cidr))
+
+		assert.Equal(t, prefix.Bits(), pseudo.Bits())
+		assert.NotEqual(t, prefix, pseudo)
+		// The pseudonyms of the addresses remain within the pseudonym of the netblock
+		assert.True(t, pseudo.Contains(r.Addr(prefix.Addr().Next())))
+	}
+}
+
+func TestASNAndOrganization(t *testing.T) {
+	r := NewRedactor([]byte("test key"))
+
+	asn := r.ASN(15169)
+	assert.GreaterOrEqual(t, asn, privateASNStart)
+	assert.Less(t, asn, privateASNStart+privateASNCount)
+	assert.Equal(t, asn, r.ASN(15169))
+	assert.NotEqual(t, asn, r.ASN(13335))
+	assert.Equal(t, 0, r.ASN(0))
+
+	org := r.Organization("Google LLC")
+	assert.True(t, strings.HasPrefix(org, "org-"))
+	assert.Equal(t, org, r.Organization(" google llc "))
+	assert.NotEqual(t, org, r.Organization("Cloudflare, Inc."))
+
+	email := r.Email("admin@owasp.org")
+	assert.True(t, strings.HasSuffix(email, "@"+r.Domain("owasp.org")))
+	assert.NotContains(t, email, "admin")
+}
+
+func TestNilRedactor(t *testing.T) {
+	var r *Redactor
+
+	assert.Equal(t, "www.owasp.org", r.Domain("www.owasp.org"))
+	assert.Equal(t, "192.0.2.1", r.IP("192.0.2.1"))
+	assert.Equal(t, "192.0.2.0/24", r.Netblock("192.0.2.0/24"))
+	assert.Equal(t, 15169, r.ASN(15169))
+	assert.Equal(t, "Google LLC", r.Organization("Google LLC"))
+	assert.Equal(t, "admin@owasp.org", r.Email("admin@owasp.org"))
+	assert.Equal(t, "serial", r.Value("serial"))
+}
+
+func commonBits(a, b netip.Addr) int {
+	x, y := a.AsSlice(), b.AsSlice()
+
+	for i := 0; i < len(x)*8; i++ {
+		mask := byte(0x80 >> (i % 8))
+		if x[i/8]&mask != y[i/8]&mask {
+			return i
+		}
+	}
+	return len(x) * 8
+}
@@ -0,0 +1,308 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package requests
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/yl2chen/cidranger"
+)
+
+// ASNCache builds a cache of ASN and netblock information. Updates are collected under a lock
+// and published as an immutable snapshot the next time the cache is searched, so any number
+// of goroutines can perform searches concurrently without acquiring the lock.
+type ASNCache struct {
+	mu      sync.Mutex
+	records map[int]*ASNRequest
+	known   map[int]map[string]struct{}
+	dirty   atomic.Bool
+	snap    atomic.Pointer[asnSnapshot]
+}
+
+// asnSnapshot is never modified after it has been published by the ASNCache.
+type asnSnapshot struct {
+	records map[int]*ASNRequest
+	ranger  cidranger.Ranger
+}
+
+type cacheRangerEntry struct {
+	IPNet net.IPNet
+	Data  *ASNRequest
+}
+
+func (e *cacheRangerEntry) Network() net.IPNet {
+	return e.IPNet
+}
+
+// NewASNCache returns an empty ASNCache for saving and searching ASN and netblock information.
+func NewASNCache() *ASNCache {
+	c := &ASNCache{
+		records: make(map[int]*ASNRequest),
+		known:   make(map[int]map[string]struct{}),
+	}
+
+	c.snap.Store(&asnSnapshot{
+		records: make(map[int]*ASNRequest),
+		ranger:  cidranger.NewPCTrieRanger(),
+	})
+	return c
+}
+
+// Insert adds the netblock announced by the asn parameter into the ASNCache.
+func (c *ASNCache) Insert(cidr string, asn int, desc string) error {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+
+	c.Update(&ASNRequest{
+		Address:     ip.String(),
+		ASN:         asn,
+		Prefix:      ipnet.String(),
+		Description: desc,
+	})
+	return nil
+}
+
+// Update saves the information in ASNRequest into the ASNCache. The new information
+// becomes visible to searches once the cache has been rebuilt by Build or the next search.
+func (c *ASNCache) Update(req *ASNRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.dirty.Store(true)
+
+	as, found := c.records[req.ASN]
+	if !found {
+		as = &ASNRequest{
+			ASN:            req.ASN,
+			Prefix:         req.Prefix,
+			CC:             req.CC,
+			Registry:       req.Registry,
+			AllocationDate: req.AllocationDate,
+			Description:    req.Description,
+		}
+		c.records[req.ASN] = as
+		c.known[req.ASN] = make(map[string]struct{})
+	} else {
+		// This is additional information for an ASN entry
+		if as.CC == "" && req.CC != "" {
+			as.CC = req.CC
+		}
+		if as.Registry == "" && req.Registry != "" {
+			as.Registry = req.Registry
+		}
+		if as.AllocationDate.IsZero() && !req.AllocationDate.IsZero() {
+			as.AllocationDate = req.AllocationDate
+		}
+		if len(as.Description) < len(req.Description) {
+			as.Description = req.Description
+		}
+	}
+
+	// Add new CIDR ranges to cached netblocks
+	known := c.known[req.ASN]
+	for _, cidr := range append([]string{req.Prefix}, req.Netblocks...) {
+		if _, dup := known[cidr]; cidr != "" && !dup {
+			known[cidr] = struct{}{}
+			as.Netblocks = append(as.Netblocks, cidr)
+		}
+	}
+}
+
+// Build publishes the updates made to the ASNCache, so the following searches do not pay
+// for rebuilding the cache. It is intended to be called once after a bulk load.
+func (c *ASNCache) Build() {
+	_ = c.snapshot()
+}
+
+// snapshot returns the current immutable view of the cache, rebuilding it when dirty.
+func (c *ASNCache) snapshot() *asnSnapshot {
+	if !c.dirty.Load() {
+		return c.snap.Load()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another goroutine may have rebuilt the snapshot while this one waited on the lock
+	if c.dirty.Load() {
+		c.snap.Store(c.buildSnapshot())
+		c.dirty.Store(false)
+	}
+	return c.snap.Load()
+}
+
+// buildSnapshot copies the records and inserts all the netblocks into a new ranger.
+// The caller must hold the lock.
+func (c *ASNCache) buildSnapshot() *asnSnapshot {
+	snap := &asnSnapshot{
+		records: make(map[int]*ASNRequest, len(c.records)),
+		ranger:  cidranger.NewPCTrieRanger(),
+	}
+
+	asns := make([]int, 0, len(c.records))
+	for asn, record := range c.records {
+		cp := *record
+		cp.Netblocks = append([]string(nil), record.Netblocks...)
+
+		snap.records[asn] = &cp
+		asns = append(asns, asn)
+	}
+	// Prefixes announced by multiple ASNs are consistently attributed to the lowest ASN
+	sort.Ints(asns)
+
+	inserted := make(map[string]struct{})
+	for _, asn := range asns {
+		record := snap.records[asn]
+
+		for _, cidr := range record.Netblocks {
+			_, ipnet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				continue
+			}
+			if ones, _ := ipnet.Mask.Size(); ones == 0 {
+				continue
+			}
+
+			key := ipnet.String()
+			if _, found := inserted[key]; found {
+				continue
+			}
+			inserted[key] = struct{}{}
+
+			_ = snap.ranger.Insert(&cacheRangerEntry{
+				IPNet: *ipnet,
+				Data:  record,
+			})
+		}
+	}
+	return snap
+}
+
+// Save writes the ASN and netblock information held by the ASNCache as JSON.
+func (c *ASNCache) Save(w io.Writer) error {
+	snap := c.snapshot()
+
+	records := make([]*ASNRequest, 0, len(snap.records))
+	for _, record := range snap.records {
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ASN < records[j].ASN
+	})
+	return json.NewEncoder(w).Encode(records)
+}
+
+// Load adds the ASN and netblock information previously written by Save into the ASNCache.
+func (c *ASNCache) Load(r io.Reader) error {
+	var records []*ASNRequest
+
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if record != nil && record.Prefix != "" {
+			c.Update(record)
+		}
+	}
+	return nil
+}
+
+// DescriptionSearch matches the provided string against description fields in the cache and
+// returns the ASN / netblock info for matching entries. The returned entries must not be modified.
+func (c *ASNCache) DescriptionSearch(s string) []*ASNRequest {
+	var matches []*ASNRequest
+
+	for _, entry := range c.snapshot().records {
+		if strings.Contains(entry.Description, s) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// ASNSearch returns the cached ASN / netblock info associated with the provided asn parameter,
+// or nil when not found in the cache. The returned entry must not be modified.
+func (c *ASNCache) ASNSearch(asn int) *ASNRequest {
+	return c.snapshot().records[asn]
+}
+
+// AddrSearch returns the cached ASN / netblock info that the addr parameter belongs in,
+// or nil when not found in the cache.
+func (c *ASNCache) AddrSearch(addr string) *ASNRequest {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil
+	}
+
+	// Does the address fall into a reserved address ranges?
+	if yes, cidr := IsReservedAddress(addr); yes {
+		return &ASNRequest{
+			Address:     addr,
+			ASN:         0,
+			Prefix:      cidr,
+			Description: "Reserved Network Address Blocks",
+		}
+	}
+
+	entry := searchRangerData(c.snapshot().ranger, ip)
+	if entry == nil {
+		return nil
+	}
+
+	netblocks := entry.Data.Netblocks
+	return &ASNRequest{
+		Address:  addr,
+		ASN:      entry.Data.ASN,
+		CC:       entry.Data.CC,
+		Registry: entry.Data.Registry,
+		Prefix:   entry.IPNet.String(),
+		// The full slice expression forces a copy if the caller appends to the netblocks
+		Netblocks:      netblocks[:len(netblocks):len(netblocks)],
+		AllocationDate: entry.Data.AllocationDate,
+		Description:    entry.Data.Description,
+	}
+}
+
+// searchRangerData returns the most specific cached netblock containing the IP address.
+func searchRangerData(ranger cidranger.Ranger, ip net.IP) *cacheRangerEntry {
+	entries, err := ranger.ContainingNetworks(ip)
+	if err != nil {
+		return nil
+	}
+
+	var best *cacheRangerEntry
+	for _, e := range entries {
+		entry, ok := e.(*cacheRangerEntry)
+		if !ok {
+			continue
+		}
+		if best == nil || compareCIDRSizes(&entry.IPNet, &best.IPNet) == 1 {
+			best = entry
+		}
+	}
+	return best
+}
+
+func compareCIDRSizes(first, second *net.IPNet) int {
+	var result int
+
+	s1, _ := first.Mask.Size()
+	s2, _ := second.Mask.Size()
+	if s1 > s2 {
+		result = 1
+	} else if s2 > s1 {
+		result = -1
+	}
+	return result
+}
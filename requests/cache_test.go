@@ -0,0 +1,333 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package requests
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testASNCache(t testing.TB) *ASNCache {
+	c := NewASNCache()
+
+	for _, entry := range []struct {
+		cidr string
+		asn  int
+		desc string
+	}{
+		{cidr: "8.8.8.0/24", asn: 15169, desc: "GOOGLE - Google LLC"},
+		{cidr: "8.34.208.0/20", asn: 15169, desc: "GOOGLE - Google LLC"},
+		{cidr: "104.16.0.0/13", asn: 13335, desc: "CLOUDFLARENET - Cloudflare, Inc."},
+		{cidr: "2606:4700::/32", asn: 13335, desc: "CLOUDFLARENET - Cloudflare, Inc."},
+		{cidr: "2001:4860::/32", asn: 15169, desc: "GOOGLE - Google LLC"},
+	} {
+		if err := c.Insert(entry.cidr, entry.asn, entry.desc); err != nil {
+			t.Fatalf("failed to insert %s: %v", entry.cidr, err)
+		}
+	}
+	return c
+}
+
+func TestInsert(t *testing.T) {
+	c := NewASNCache()
+
+	assert.NotNil(t, c.Insert("not-a-cidr", 64500, "TEST"))
+	assert.Nil(t, c.ASNSearch(64500))
+
+	assert.Nil(t, c.Insert("192.0.2.77/24", 64500, "TEST"))
+	if as := c.ASNSearch(64500); assert.NotNil(t, as) {
+		assert.Equal(t, "192.0.2.0/24", as.Prefix)
+		assert.Equal(t, []string{"192.0.2.0/24"}, as.Netblocks)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	c := NewASNCache()
+
+	c.Update(&ASNRequest{ASN: 64500, Prefix: "198.51.100.0/24", Description: "TEST"})
+	c.Update(&ASNRequest{
+		ASN:         64500,
+		Prefix:      "198.51.100.0/24",
+		CC:          "US",
+		Registry:    "ARIN",
+		Description: "TEST - Test Networks",
+		Netblocks:   []string{"203.0.113.0/24"},
+	})
+	c.Update(&ASNRequest{ASN: 64500, Prefix: "203.0.113.0/24", CC: "DE", Description: "TEST"})
+
+	as := c.ASNSearch(64500)
+	if assert.NotNil(t, as) {
+		assert.Equal(t, "US", as.CC)
+		assert.Equal(t, "ARIN", as.Registry)
+		assert.Equal(t, "TEST - Test Networks", as.Description)
+		assert.ElementsMatch(t, []string{"198.51.100.0/24", "203.0.113.0/24"}, as.Netblocks)
+	}
+}
+
+func TestAddrSearch(t *testing.T) {
+	c := testASNCache(t)
+
+	tests := []struct {
+		addr   string
+		asn    int
+		prefix string
+		found  bool
+	}{
+		{addr: "8.8.8.8", asn: 15169, prefix: "8.8.8.0/24", found: true},
+		{addr: "8.34.210.1", asn: 15169, prefix: "8.34.208.0/20", found: true},
+		{addr: "104.18.2.3", asn: 13335, prefix: "104.16.0.0/13", found: true},
+		{addr: "2606:4700::6810:84e5", asn: 13335, prefix: "2606:4700::/32", found: true},
+		{addr: "2001:4860:4860::8888", asn: 15169, prefix: "2001:4860::/32", found: true},
+		{addr: "10.1.2.3", asn: 0, prefix: "10.0.0.0/8", found: true},
+		{addr: "9.9.9.9"},
+		{addr: "not-an-address"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.addr, func(t *testing.T) {
+			req := c.AddrSearch(tc.addr)
+			if !tc.found {
+				assert.Nil(t, req)
+				return
+			}
+			if assert.NotNil(t, req) {
+				assert.Equal(t, tc.addr, req.Address)
+				assert.Equal(t, tc.asn, req.ASN)
+				assert.Equal(t, tc.prefix, req.Prefix)
+			}
+		})
+	}
+}
+
+func TestAddrSearchNestedAnnouncements(t *testing.T) {
+	broad := []struct {
+		cidr string
+		asn  int
+	}{
+		{cidr: "44.0.0.0/8", asn: 7377},
+		{cidr: "44.128.0.0/10", asn: 64496},
+		{cidr: "2a00:1450::/29", asn: 15169},
+	}
+	narrow := []struct {
+		cidr string
+		asn  int
+	}{
+		{cidr: "44.128.4.0/22", asn: 64497},
+		{cidr: "44.128.4.128/25", asn: 64498},
+		{cidr: "2a00:1450:4001::/48", asn: 64499},
+	}
+
+	tests := []struct {
+		addr   string
+		asn    int
+		prefix string
+	}{
+		{addr: "44.1.2.3", asn: 7377, prefix: "44.0.0.0/8"},
+		{addr: "44.130.0.1", asn: 64496, prefix: "44.128.0.0/10"},
+		{addr: "44.128.5.1", asn: 64497, prefix: "44.128.4.0/22"},
+		{addr: "44.128.4.200", asn: 64498, prefix: "44.128.4.128/25"},
+		{addr: "44.128.4.100", asn: 64497, prefix: "44.128.4.0/22"},
+		{addr: "2a00:1450:4002::1", asn: 15169, prefix: "2a00:1450::/29"},
+		{addr: "2a00:1450:4001::1", asn: 64499, prefix: "2a00:1450:4001::/48"},
+	}
+
+	// The broader announcements are cached and searched before the nested announcements arrive
+	c := NewASNCache()
+	for _, b := range broad {
+		assert.Nil(t, c.Insert(b.cidr, b.asn, fmt.Sprintf("AS%d", b.asn)))
+	}
+	for _, tc := range tests {
+		assert.NotNil(t, c.AddrSearch(tc.addr))
+	}
+	for _, n := range narrow {
+		assert.Nil(t, c.Insert(n.cidr, n.asn, fmt.Sprintf("AS%d", n.asn)))
+	}
+
+	// The same announcements inserted with the most specific prefixes first
+	reversed := NewASNCache()
+	for i := len(narrow) - 1; i >= 0; i-- {
+		assert.Nil(t, reversed.Insert(narrow[i].cidr, narrow[i].asn, fmt.Sprintf("AS%d", narrow[i].asn)))
+	}
+	for i := len(broad) - 1; i >= 0; i-- {
+		assert.Nil(t, reversed.Insert(broad[i].cidr, broad[i].asn, fmt.Sprintf("AS%d", broad[i].asn)))
+	}
+
+	for _, cache := range []*ASNCache{c, reversed} {
+		for _, tc := range tests {
+			if req := cache.AddrSearch(tc.addr); assert.NotNil(t, req, tc.addr) {
+				assert.Equal(t, tc.asn, req.ASN, tc.addr)
+				assert.Equal(t, tc.prefix, req.Prefix, tc.addr)
+			}
+		}
+	}
+}
+
+func TestConcurrentSearchAndUpdate(t *testing.T) {
+	c := testASNCache(t)
+	c.Build()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				if req := c.AddrSearch("8.8.8.8"); assert.NotNil(t, req) {
+					assert.Equal(t, 15169, req.ASN)
+				}
+				_ = c.DescriptionSearch("GOOGLE")
+				_ = c.Insert(fmt.Sprintf("45.%d.%d.0/24", i, j), 64512+i, "TEST")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 8; i++ {
+		if req := c.ASNSearch(64512 + i); assert.NotNil(t, req) {
+			assert.Len(t, req.Netblocks, 50)
+		}
+	}
+	if req := c.AddrSearch("45.7.49.1"); assert.NotNil(t, req) {
+		assert.Equal(t, 64519, req.ASN)
+	}
+}
+
+func TestASNSearch(t *testing.T) {
+	c := testASNCache(t)
+
+	tests := []struct {
+		asn       int
+		netblocks []string
+	}{
+		{asn: 15169, netblocks: []string{"8.8.8.0/24", "8.34.208.0/20", "2001:4860::/32"}},
+		{asn: 13335, netblocks: []string{"104.16.0.0/13", "2606:4700::/32"}},
+		{asn: 64500},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("AS%d", tc.asn), func(t *testing.T) {
+			req := c.ASNSearch(tc.asn)
+			if tc.netblocks == nil {
+				assert.Nil(t, req)
+				return
+			}
+			if assert.NotNil(t, req) {
+				assert.ElementsMatch(t, tc.netblocks, req.Netblocks)
+			}
+		})
+	}
+}
+
+func TestDescriptionSearch(t *testing.T) {
+	c := testASNCache(t)
+
+	tests := []struct {
+		search string
+		asns   []int
+	}{
+		{search: "GOOGLE", asns: []int{15169}},
+		{search: "Cloudflare", asns: []int{13335}},
+		{search: " - ", asns: []int{13335, 15169}},
+		{search: "AMAZON"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.search, func(t *testing.T) {
+			var asns []int
+			for _, req := range c.DescriptionSearch(tc.search) {
+				asns = append(asns, req.ASN)
+			}
+			assert.ElementsMatch(t, tc.asns, asns)
+		})
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	c := testASNCache(t)
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, c.Save(buf))
+
+	loaded := NewASNCache()
+	assert.Nil(t, loaded.Load(buf))
+	for _, asn := range []int{13335, 15169} {
+		if req := loaded.ASNSearch(asn); assert.NotNil(t, req) {
+			assert.ElementsMatch(t, c.ASNSearch(asn).Netblocks, req.Netblocks)
+			assert.Equal(t, c.ASNSearch(asn).Description, req.Description)
+		}
+	}
+	if req := loaded.AddrSearch("8.8.8.8"); assert.NotNil(t, req) {
+		assert.Equal(t, 15169, req.ASN)
+	}
+
+	assert.NotNil(t, NewASNCache().Load(bytes.NewBufferString("{")))
+}
+
+func BenchmarkAddrSearch(b *testing.B) {
+	c := benchmarkASNCache(b)
+	c.Build()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = c.AddrSearch(fmt.Sprintf("44.%d.%d.1", (i/256)%64, i%256))
+	}
+}
+
+func BenchmarkAddrSearchParallel(b *testing.B) {
+	c := benchmarkASNCache(b)
+	c.Build()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			_ = c.AddrSearch(fmt.Sprintf("44.%d.%d.1", (i/256)%64, i%256))
+			i++
+		}
+	})
+}
+
+func BenchmarkASNSearchParallel(b *testing.B) {
+	c := benchmarkASNCache(b)
+	c.Build()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			_ = c.ASNSearch(64512 + i%64)
+			i++
+		}
+	})
+}
+
+func BenchmarkUpdate(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = benchmarkASNCache(b)
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		benchmarkASNCache(b).Build()
+	}
+}
+
+// benchmarkASNCache returns a cache holding 16,384 /24 netblocks announced by 64 autonomous systems.
+func benchmarkASNCache(b *testing.B) *ASNCache {
+	c := NewASNCache()
+
+	for i := 0; i < 64; i++ {
+		for j := 0; j < 256; j++ {
+			_ = c.Insert(fmt.Sprintf("44.%d.%d.0/24", i, j), 64512+i, fmt.Sprintf("AS%d", 64512+i))
+		}
+	}
+	return c
+}
@@ -0,0 +1,184 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package requests
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ImportASNDataset reads the offline IP-to-ASN dataset at path into the ASNCache and returns
+// the number of prefixes that were imported. Both the iptoasn.com TSV format and the
+// CAIDA RouteViews pfx2as format are supported, optionally compressed with gzip.
+func ImportASNDataset(cache *ASNCache, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return 0, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return importASNRecords(cache, r)
+}
+
+func importASNRecords(cache *ASNCache, r io.Reader) (int, error) {
+	var count int
+	records := make(map[int]*ASNRequest)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var err error
+		var asn int
+		var cc, desc string
+		var prefixes []netip.Prefix
+		fields := strings.Split(line, "\t")
+		switch len(fields) {
+		case 3:
+			// RouteViews pfx2as: prefix, length, origin ASN
+			asn, prefixes, err = parsePfx2asRecord(fields)
+		case 5:
+			// iptoasn: range start, range end, ASN, country code, description
+			asn, prefixes, err = parseIPToASNRecord(fields)
+			cc, desc = fields[3], fields[4]
+		default:
+			err = errors.New("unknown record format")
+		}
+		if err != nil || asn == 0 || len(prefixes) == 0 {
+			continue
+		}
+
+		rec, found := records[asn]
+		if !found {
+			rec = &ASNRequest{
+				ASN:         asn,
+				Prefix:      prefixes[0].String(),
+				CC:          cc,
+				Description: desc,
+			}
+			records[asn] = rec
+		}
+		for _, p := range prefixes {
+			rec.Netblocks = append(rec.Netblocks, p.String())
+		}
+		count += len(prefixes)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	for asn, rec := range records {
+		if rec.Description == "" {
+			rec.Description = "AS" + strconv.Itoa(asn)
+		}
+		cache.Update(rec)
+	}
+	return count, nil
+}
+
+func parsePfx2asRecord(fields []string) (int, []netip.Prefix, error) {
+	bits, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	prefix, err := netip.ParsePrefix(fields[0] + "/" + strconv.Itoa(bits))
+	if err != nil {
+		return 0, nil, err
+	}
+	// Multi-origin prefixes and AS sets are attributed to the first ASN listed
+	origin := strings.FieldsFunc(fields[2], func(r rune) bool {
+		return r == '_' || r == ','
+	})
+	if len(origin) == 0 {
+		return 0, nil, errors.New("the record is missing the origin ASN")
+	}
+
+	asn, err := strconv.Atoi(origin[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	return asn, []netip.Prefix{prefix.Masked()}, nil
+}
+
+func parseIPToASNRecord(fields []string) (int, []netip.Prefix, error) {
+	start, err := netip.ParseAddr(fields[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	end, err := netip.ParseAddr(fields[1])
+	if err != nil {
+		return 0, nil, err
+	}
+	if start.Is4() != end.Is4() || end.Less(start) {
+		return 0, nil, errors.New("the address range is invalid")
+	}
+
+	asn, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return 0, nil, err
+	}
+	return asn, rangeToPrefixes(start, end), nil
+}
+
+// rangeToPrefixes returns the smallest set of CIDR blocks covering the inclusive address range.
+func rangeToPrefixes(start, end netip.Addr) []netip.Prefix {
+	var prefixes []netip.Prefix
+
+	for start.IsValid() && !end.Less(start) {
+		bits := start.BitLen()
+		// Grow the block while it stays aligned on start and ends within the range
+		for bits > 0 {
+			p := netip.PrefixFrom(start, bits-1).Masked()
+			if p.Addr() != start || end.Less(lastAddr(p)) {
+				break
+			}
+			bits--
+		}
+
+		p := netip.PrefixFrom(start, bits)
+		prefixes = append(prefixes, p)
+		start = lastAddr(p).Next()
+	}
+	return prefixes
+}
+
+func lastAddr(p netip.Prefix) netip.Addr {
+	a := p.Masked().Addr()
+
+	if a.Is4() {
+		b := a.As4()
+		for i := p.Bits(); i < 32; i++ {
+			b[i/8] |= 1 << (7 - uint(i%8))
+		}
+		return netip.AddrFrom4(b)
+	}
+
+	b := a.As16()
+	for i := p.Bits(); i < 128; i++ {
+		b[i/8] |= 1 << (7 - uint(i%8))
+	}
+	return netip.AddrFrom16(b)
+}
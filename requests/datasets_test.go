@@ -0,0 +1,140 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package requests
+
+import (
+	"compress/gzip"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testIPToASN = `1.0.0.0	1.0.0.255	13335	US	CLOUDFLARENET
+1.0.1.0	1.0.3.255	0	None	Not routed
+1.0.4.0	1.0.6.255	64500	AU	TEST-AS
+2606:4700::	2606:4700:ffff:ffff:ffff:ffff:ffff:ffff	13335	US	CLOUDFLARENET
+`
+
+const testPfx2as = `# comment lines are skipped
+8.8.8.0	24	15169
+203.0.113.0	24	64501_64502
+198.51.100.0	24	64503,64504
+malformed record
+`
+
+func TestImportIPToASN(t *testing.T) {
+	c := NewASNCache()
+
+	num, err := importASNRecords(c, strings.NewReader(testIPToASN))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, num)
+
+	if req := c.AddrSearch("1.0.0.1"); assert.NotNil(t, req) {
+		assert.Equal(t, 13335, req.ASN)
+		assert.Equal(t, "US", req.CC)
+		assert.Equal(t, "CLOUDFLARENET", req.Description)
+	}
+	if req := c.AddrSearch("1.0.6.1"); assert.NotNil(t, req) {
+		assert.Equal(t, 64500, req.ASN)
+		assert.Equal(t, "1.0.6.0/24", req.Prefix)
+	}
+	if req := c.AddrSearch("2606:4700::1"); assert.NotNil(t, req) {
+		assert.Equal(t, 13335, req.ASN)
+		assert.Equal(t, "2606:4700::/32", req.Prefix)
+	}
+	assert.Nil(t, c.AddrSearch("1.0.2.1"))
+	assert.Nil(t, c.ASNSearch(0))
+}
+
+func TestImportPfx2as(t *testing.T) {
+	c := NewASNCache()
+
+	num, err := importASNRecords(c, strings.NewReader(testPfx2as))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, num)
+
+	tests := []struct {
+		addr string
+		asn  int
+		desc string
+	}{
+		{addr: "8.8.8.8", asn: 15169, desc: "AS15169"},
+		{addr: "203.0.113.1", asn: 64501, desc: "AS64501"},
+		{addr: "198.51.100.1", asn: 64503, desc: "AS64503"},
+	}
+
+	for _, tc := range tests {
+		if req := c.AddrSearch(tc.addr); assert.NotNil(t, req, tc.addr) {
+			assert.Equal(t, tc.asn, req.ASN)
+			assert.Equal(t, tc.desc, req.Description)
+		}
+	}
+}
+
+func TestImportASNDatasetGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ip2asn.tsv.gz")
+
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	gz := gzip.NewWriter(f)
+	_, err = gz.Write([]byte(testIPToASN))
+	assert.Nil(t, err)
+	assert.Nil(t, gz.Close())
+	assert.Nil(t, f.Close())
+
+	c := NewASNCache()
+	num, err := ImportASNDataset(c, path)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, num)
+	assert.NotNil(t, c.ASNSearch(13335))
+
+	_, err = ImportASNDataset(c, filepath.Join(t.TempDir(), "missing.tsv"))
+	assert.NotNil(t, err)
+}
+
+func TestRangeToPrefixes(t *testing.T) {
+	tests := []struct {
+		start    string
+		end      string
+		expected []string
+	}{
+		{start: "10.0.0.0", end: "10.0.0.255", expected: []string{"10.0.0.0/24"}},
+		{start: "10.0.0.0", end: "10.0.2.255", expected: []string{"10.0.0.0/23", "10.0.2.0/24"}},
+		{start: "10.0.0.1", end: "10.0.0.4", expected: []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/32"}},
+		{start: "0.0.0.0", end: "255.255.255.255", expected: []string{"0.0.0.0/0"}},
+		{start: "255.255.255.255", end: "255.255.255.255", expected: []string{"255.255.255.255/32"}},
+		{start: "2001:db8::", end: "2001:db8::ffff", expected: []string{"2001:db8::/112"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.start+"-"+tc.end, func(t *testing.T) {
+			var got []string
+			for _, p := range rangeToPrefixes(netip.MustParseAddr(tc.start), netip.MustParseAddr(tc.end)) {
+				got = append(got, p.String())
+			}
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func BenchmarkImportASNRecords(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 256; i++ {
+		for j := 0; j < 64; j++ {
+			sb.WriteString("44." + strconv.Itoa(i) + "." + strconv.Itoa(j*4) + ".0\t44." + strconv.Itoa(i) + "." + strconv.Itoa(j*4+3) + ".255\t" + strconv.Itoa(64512+i) + "\tUS\tTEST\n")
+		}
+	}
+	data := sb.String()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = importASNRecords(NewASNCache(), strings.NewReader(data))
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package requests
+
+import (
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/network"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+)
+
+// FillCache populates the ASNCache with the autonomous systems, registration data and
+// announced netblocks found in the graph database.
+func FillCache(cache *ASNCache, db *graph.Graph) error {
+	start := time.Now().Add(-730 * time.Hour)
+	assets, err := db.DB.FindByType(oam.AutonomousSystem, start)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range assets {
+		as, ok := a.Asset.(*network.AutonomousSystem)
+		if !ok {
+			continue
+		}
+
+		autnum := autnumRecord(db, a, start)
+		if autnum == nil {
+			continue
+		}
+
+		desc := autnum.Handle + " - " + autnum.Name
+		registry := RegistryFromWhoisServer(autnum.WhoisServer)
+		created, _ := time.Parse(time.RFC3339, autnum.CreatedDate)
+
+		rels, err := db.DB.OutgoingRelations(a, start, "announces")
+		if err != nil {
+			continue
+		}
+
+		for _, rel := range rels {
+			asset, err := db.DB.FindById(rel.ToAsset.ID, start)
+			if err != nil || asset == nil {
+				continue
+			}
+
+			nb, ok := asset.Asset.(*network.Netblock)
+			if !ok || nb.CIDR.Bits() == 0 {
+				continue
+			}
+
+			var cc string
+			if ipnet := ipnetRecord(db, asset, start); ipnet != nil {
+				cc = strings.ToUpper(ipnet.Country)
+				if registry == "" {
+					registry = RegistryFromWhoisServer(ipnet.WhoisServer)
+				}
+			}
+
+			cidr := nb.CIDR.Masked()
+			cache.Update(&ASNRequest{
+				Address:        cidr.Addr().String(),
+				ASN:            as.Number,
+				Prefix:         cidr.String(),
+				CC:             cc,
+				Registry:       registry,
+				AllocationDate: created,
+				Description:    desc,
+			})
+		}
+	}
+	return nil
+}
+
+// OutgoingAssets returns the assets that the asset points to through the relations, or through
+// any relation when none are provided.
+func OutgoingAssets(g *graph.Graph, asset *types.Asset, since time.Time, rtypes ...string) []*types.Asset {
+	var results []*types.Asset
+
+	if rels, err := g.DB.OutgoingRelations(asset, since, rtypes...); err == nil {
+		for _, rel := range rels {
+			if to, err := g.DB.FindById(rel.ToAsset.ID, since); err == nil && to != nil && to.Asset != nil {
+				results = append(results, to)
+			}
+		}
+	}
+	return results
+}
+
+// IncomingAssets returns the assets pointing to the asset through the relations, or through
+// any relation when none are provided.
+func IncomingAssets(g *graph.Graph, asset *types.Asset, since time.Time, rtypes ...string) []*types.Asset {
+	var results []*types.Asset
+
+	if rels, err := g.DB.IncomingRelations(asset, since, rtypes...); err == nil {
+		for _, rel := range rels {
+			if from, err := g.DB.FindById(rel.FromAsset.ID, since); err == nil && from != nil && from.Asset != nil {
+				results = append(results, from)
+			}
+		}
+	}
+	return results
+}
+
+// DomainNameInScope checks if the name is one of the root domain names or a subdomain of them.
+func DomainNameInScope(name string, scope []string) bool {
+	n := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+
+	for _, d := range scope {
+		d = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(d), "."))
+
+		if d != "" && (n == d || strings.HasSuffix(n, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegistryFromWhoisServer returns the name of the regional Internet registry operating the WHOIS server.
+func RegistryFromWhoisServer(server string) string {
+	server = strings.ToLower(server)
+
+	for _, rir := range []string{"afrinic", "apnic", "arin", "lacnic", "ripe"} {
+		if strings.Contains(server, rir) {
+			return strings.ToUpper(rir)
+		}
+	}
+	return ""
+}
+
+func autnumRecord(db *graph.Graph, as *types.Asset, since time.Time) *oamreg.AutnumRecord {
+	rels, err := db.DB.OutgoingRelations(as, since, "registration")
+	if err != nil {
+		return nil
+	}
+
+	for _, rel := range rels {
+		if asset, err := db.DB.FindById(rel.ToAsset.ID, since); err == nil && asset != nil {
+			if autnum, ok := asset.Asset.(*oamreg.AutnumRecord); ok && autnum != nil {
+				return autnum
+			}
+		}
+	}
+	return nil
+}
+
+func ipnetRecord(db *graph.Graph, netblock *types.Asset, since time.Time) *oamreg.IPNetRecord {
+	rels, err := db.DB.OutgoingRelations(netblock, since, "registration")
+	if err != nil {
+		return nil
+	}
+
+	for _, rel := range rels {
+		if asset, err := db.DB.FindById(rel.ToAsset.ID, since); err == nil && asset != nil {
+			if ipnet, ok := asset.Asset.(*oamreg.IPNetRecord); ok && ipnet != nil {
+				return ipnet
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package requests
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/open-asset-model/network"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFillCache(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+
+	as, err := g.DB.Create(nil, "", &network.AutonomousSystem{Number: 64510})
+	assert.Nil(t, err)
+	_, err = g.DB.Create(as, "registration", &oamreg.AutnumRecord{
+		Number:      64510,
+		Handle:      "AS64510",
+		Name:        "FILLCACHE-TEST",
+		WhoisServer: "whois.ripe.net",
+		CreatedDate: "2011-03-04T00:00:00Z",
+	})
+	assert.Nil(t, err)
+
+	nb, err := g.DB.Create(as, "announces", &network.Netblock{CIDR: netip.MustParsePrefix("198.18.0.0/15"), Type: "IPv4"})
+	assert.Nil(t, err)
+	_, err = g.DB.Create(nb, "registration", &oamreg.IPNetRecord{
+		CIDR:    netip.MustParsePrefix("198.18.0.0/15"),
+		Handle:  "NET-198-18-0-0-1",
+		Country: "nl",
+	})
+	assert.Nil(t, err)
+
+	// An autonomous system without registration data is not added to the cache
+	_, err = g.DB.Create(nil, "", &network.AutonomousSystem{Number: 64511})
+	assert.Nil(t, err)
+
+	c := NewASNCache()
+	assert.Nil(t, FillCache(c, g))
+	assert.Nil(t, c.ASNSearch(64511))
+
+	req := c.ASNSearch(64510)
+	if assert.NotNil(t, req) {
+		assert.Equal(t, "AS64510 - FILLCACHE-TEST", req.Description)
+		assert.Equal(t, "NL", req.CC)
+		assert.Equal(t, "RIPE", req.Registry)
+		assert.Equal(t, time.Date(2011, 3, 4, 0, 0, 0, 0, time.UTC), req.AllocationDate.UTC())
+		assert.Equal(t, []string{"198.18.0.0/15"}, req.Netblocks)
+	}
+}
+
+func TestRegistryFromWhoisServer(t *testing.T) {
+	tests := []struct {
+		server   string
+		registry string
+	}{
+		{server: "whois.arin.net", registry: "ARIN"},
+		{server: "WHOIS.RIPE.NET", registry: "RIPE"},
+		{server: "whois.apnic.net", registry: "APNIC"},
+		{server: "whois.lacnic.net", registry: "LACNIC"},
+		{server: "whois.afrinic.net", registry: "AFRINIC"},
+		{server: "whois.example.com"},
+		{server: ""},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.registry, RegistryFromWhoisServer(tc.server), tc.server)
+	}
+}
+
+func TestRelatedAssets(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+
+	as, err := g.DB.Create(nil, "", &network.AutonomousSystem{Number: 64512})
+	assert.Nil(t, err)
+	nb, err := g.DB.Create(as, "announces", &network.Netblock{CIDR: netip.MustParsePrefix("198.51.100.0/24"), Type: "IPv4"})
+	assert.Nil(t, err)
+	_, err = g.DB.Create(as, "registration", &oamreg.AutnumRecord{Number: 64512, Handle: "AS64512"})
+	assert.Nil(t, err)
+
+	out := OutgoingAssets(g, as, time.Time{}, "announces")
+	if assert.Len(t, out, 1) {
+		assert.Equal(t, nb.ID, out[0].ID)
+	}
+	assert.Len(t, OutgoingAssets(g, as, time.Time{}), 2)
+	assert.Empty(t, OutgoingAssets(g, as, time.Time{}, "contains"))
+
+	in := IncomingAssets(g, nb, time.Time{}, "announces")
+	if assert.Len(t, in, 1) {
+		assert.Equal(t, as.ID, in[0].ID)
+	}
+	assert.Empty(t, IncomingAssets(g, as, time.Time{}))
+}
+
+func TestDomainNameInScope(t *testing.T) {
+	scope := []string{"owasp.org", "Example.COM."}
+
+	tests := []struct {
+		name    string
+		inscope bool
+	}{
+		{name: "owasp.org", inscope: true},
+		{name: "www.owasp.org", inscope: true},
+		{name: "WWW.OWASP.ORG.", inscope: true},
+		{name: " api.example.com ", inscope: true},
+		{name: "notowasp.org"},
+		{name: "owasp.org.evil.net"},
+		{name: ""},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.inscope, DomainNameInScope(tc.name, scope), tc.name)
+	}
+	assert.False(t, DomainNameInScope("owasp.org", []string{""}))
+}
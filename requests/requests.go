@@ -0,0 +1,42 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package requests provides the types and ASN cache used to attribute discovered addresses
+// to the autonomous systems and netblocks they belong to, along with helpers to walk the graph.
+package requests
+
+import (
+	"net"
+	"time"
+)
+
+// Output contains all the output data for an enumerated DNS name.
+type Output struct {
+	Name      string        `json:"name"`
+	Addresses []AddressInfo `json:"addresses"`
+}
+
+// AddressInfo stores all network addressing info for the Output type.
+type AddressInfo struct {
+	Address        net.IP     `json:"ip"`
+	Netblock       *net.IPNet `json:"-"`
+	CIDRStr        string     `json:"cidr"`
+	ASN            int        `json:"asn"`
+	Description    string     `json:"desc"`
+	CC             string     `json:"cc,omitempty"`
+	Registry       string     `json:"registry,omitempty"`
+	AllocationDate time.Time  `json:"-"`
+}
+
+// ASNRequest handles all autonomous system information needed by Amass.
+type ASNRequest struct {
+	Address        string    `json:"address,omitempty"`
+	ASN            int       `json:"asn"`
+	Prefix         string    `json:"prefix"`
+	CC             string    `json:"cc,omitempty"`
+	Registry       string    `json:"registry,omitempty"`
+	AllocationDate time.Time `json:"allocation_date"`
+	Description    string    `json:"desc"`
+	Netblocks      []string  `json:"netblocks"`
+}
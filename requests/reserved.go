@@ -0,0 +1,71 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package requests
+
+import "net"
+
+var reservedCIDRs = []string{
+	"192.168.0.0/16",
+	"172.16.0.0/12",
+	"10.0.0.0/8",
+	"127.0.0.0/8",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"100.64.0.0/10",
+	"198.18.0.0/15",
+	"169.254.0.0/16",
+	"192.88.99.0/24",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"192.94.77.0/24",
+	"192.94.78.0/24",
+	"192.52.193.0/24",
+	"192.12.109.0/24",
+	"192.31.196.0/24",
+	"192.0.0.0/29",
+	"0.0.0.0/8",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"64:ff9b:1::/48",
+	"100::/64",
+	"2001:db8::/32",
+	"2002::/16",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+}
+
+// The reserved network address ranges
+var reservedAddrRanges []*net.IPNet
+
+func init() {
+	for _, cidr := range reservedCIDRs {
+		if _, ipnet, err := net.ParseCIDR(cidr); err == nil {
+			reservedAddrRanges = append(reservedAddrRanges, ipnet)
+		}
+	}
+}
+
+// IsReservedAddress checks if the addr parameter is within one of the address ranges
+// reserved for special use, and returns the most specific matching CIDR.
+func IsReservedAddress(addr string) (bool, string) {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false, ""
+	}
+
+	var match *net.IPNet
+	for _, block := range reservedAddrRanges {
+		if block.Contains(ip) && (match == nil || compareCIDRSizes(block, match) == 1) {
+			match = block
+		}
+	}
+
+	if match != nil {
+		return true, match.String()
+	}
+	return false, ""
+}
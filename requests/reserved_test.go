@@ -0,0 +1,57 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package requests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsReservedAddress(t *testing.T) {
+	tests := []struct {
+		addr     string
+		reserved bool
+		cidr     string
+	}{
+		{addr: "192.168.1.1", reserved: true, cidr: "192.168.0.0/16"},
+		{addr: "172.20.0.1", reserved: true, cidr: "172.16.0.0/12"},
+		{addr: "10.10.10.10", reserved: true, cidr: "10.0.0.0/8"},
+		{addr: "127.0.0.1", reserved: true, cidr: "127.0.0.0/8"},
+		{addr: "100.64.1.1", reserved: true, cidr: "100.64.0.0/10"},
+		{addr: "169.254.169.254", reserved: true, cidr: "169.254.0.0/16"},
+		{addr: "192.0.0.5", reserved: true, cidr: "192.0.0.0/29"},
+		{addr: "192.0.0.9", reserved: true, cidr: "192.0.0.0/24"},
+		{addr: "0.1.2.3", reserved: true, cidr: "0.0.0.0/8"},
+		{addr: "::", reserved: true, cidr: "::/128"},
+		{addr: "::1", reserved: true, cidr: "::1/128"},
+		{addr: "fd12:3456:789a::1", reserved: true, cidr: "fc00::/7"},
+		{addr: "fe80::1", reserved: true, cidr: "fe80::/10"},
+		{addr: "2001:db8:1::1", reserved: true, cidr: "2001:db8::/32"},
+		{addr: "2002:c000:204::1", reserved: true, cidr: "2002::/16"},
+		{addr: "64:ff9b::c000:201", reserved: true, cidr: "64:ff9b::/96"},
+		{addr: "100::1", reserved: true, cidr: "100::/64"},
+		{addr: "ff02::1", reserved: true, cidr: "ff00::/8"},
+		{addr: "8.8.8.8"},
+		{addr: "2001:4860:4860::8888"},
+		{addr: "::ffff:8.8.8.8"},
+		{addr: "172.32.0.1"},
+		{addr: "bad-address"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.addr, func(t *testing.T) {
+			reserved, cidr := IsReservedAddress(tc.addr)
+			assert.Equal(t, tc.reserved, reserved)
+			assert.Equal(t, tc.cidr, cidr)
+		})
+	}
+}
+
+func BenchmarkIsReservedAddress(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = IsReservedAddress("203.0.114.1")
+	}
+}
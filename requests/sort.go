@@ -0,0 +1,125 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package requests
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// Orderings supported by SortOutput and SortNames.
+const (
+	// SortByName orders the names lexically
+	SortByName = "name"
+	// SortByRDNS orders the names by their labels from right to left, grouping each subdomain
+	SortByRDNS = "rdns"
+	// SortByASN orders the names by the lowest ASN of their addresses
+	SortByASN = "asn"
+	// SortByCount orders the names by their number of addresses, in descending order
+	SortByCount = "count"
+)
+
+// SortOrders lists the orderings supported by SortOutput.
+var SortOrders = []string{SortByName, SortByRDNS, SortByASN, SortByCount}
+
+// ValidSortOrder returns true when the order is supported by SortOutput.
+func ValidSortOrder(order string) bool {
+	for _, o := range SortOrders {
+		if order == o {
+			return true
+		}
+	}
+	return false
+}
+
+// SortOutput orders the names, and the addresses of each name, in place. Ties are broken by
+// the lexical order of the names, so the result does not depend on the order of the input.
+func SortOutput(names []*Output, order string) {
+	for _, out := range names {
+		SortAddresses(out.Addresses)
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := names[i], names[j]
+
+		switch order {
+		case SortByRDNS:
+			if c := CompareReverseDNS(a.Name, b.Name); c != 0 {
+				return c < 0
+			}
+		case SortByASN:
+			if ai, bi := lowestASN(a), lowestASN(b); ai != bi {
+				return ai < bi
+			}
+		case SortByCount:
+			if len(a.Addresses) != len(b.Addresses) {
+				return len(a.Addresses) > len(b.Addresses)
+			}
+		}
+		return a.Name < b.Name
+	})
+}
+
+// SortNames orders the names in place. The SortByASN and SortByCount orders need address
+// information and are applied as SortByName.
+func SortNames(names []string, order string) {
+	sort.SliceStable(names, func(i, j int) bool {
+		if order == SortByRDNS {
+			if c := CompareReverseDNS(names[i], names[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return names[i] < names[j]
+	})
+}
+
+// SortAddresses orders the addresses in place, with IPv4 addresses before IPv6 addresses.
+func SortAddresses(addrs []AddressInfo) {
+	sort.SliceStable(addrs, func(i, j int) bool {
+		a, b := addrs[i].Address, addrs[j].Address
+
+		if a4, b4 := a.To4() != nil, b.To4() != nil; a4 != b4 {
+			return a4
+		}
+		return bytes.Compare(a.To16(), b.To16()) < 0
+	})
+}
+
+// CompareReverseDNS compares the names label by label starting from the rightmost label.
+// The result is 0 if a == b, -1 if a < b and +1 if a > b.
+func CompareReverseDNS(a, b string) int {
+	al := strings.Split(strings.ToLower(strings.TrimSuffix(a, ".")), ".")
+	bl := strings.Split(strings.ToLower(strings.TrimSuffix(b, ".")), ".")
+
+	for i, j := len(al)-1, len(bl)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if c := strings.Compare(al[i], bl[j]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(al) < len(bl):
+		return -1
+	case len(al) > len(bl):
+		return 1
+	}
+	return 0
+}
+
+func lowestASN(out *Output) int {
+	lowest := -1
+
+	for _, addr := range out.Addresses {
+		if lowest == -1 || addr.ASN < lowest {
+			lowest = addr.ASN
+		}
+	}
+	if lowest == -1 {
+		// Names without addresses are placed last
+		return int(^uint(0) >> 1)
+	}
+	return lowest
+}
@@ -0,0 +1,129 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package requests
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSortOutput() []*Output {
+	addr := func(ip string, asn int) AddressInfo {
+		return AddressInfo{Address: net.ParseIP(ip), ASN: asn}
+	}
+
+	return []*Output{
+		{Name: "www.example.com", Addresses: []AddressInfo{addr("2606:4700::1", 13335), addr("104.16.1.1", 13335)}},
+		{Name: "api.dev.example.com", Addresses: []AddressInfo{addr("8.8.8.8", 15169)}},
+		{Name: "example.com", Addresses: []AddressInfo{addr("93.184.216.34", 15133)}},
+		{Name: "mail.example.org"},
+		{Name: "dev.example.com", Addresses: []AddressInfo{addr("10.0.0.2", 0), addr("10.0.0.1", 0), addr("8.8.4.4", 15169)}},
+		{Name: "b.example.com", Addresses: []AddressInfo{addr("8.8.8.8", 15169)}},
+	}
+}
+
+func outputNames(names []*Output) []string {
+	var list []string
+
+	for _, n := range names {
+		list = append(list, n.Name)
+	}
+	return list
+}
+
+func TestSortOutput(t *testing.T) {
+	tests := []struct {
+		order    string
+		expected []string
+	}{
+		{
+			order: SortByName,
+			expected: []string{"api.dev.example.com", "b.example.com", "dev.example.com",
+				"example.com", "mail.example.org", "www.example.com"},
+		},
+		{
+			order: SortByRDNS,
+			expected: []string{"example.com", "b.example.com", "dev.example.com",
+				"api.dev.example.com", "www.example.com", "mail.example.org"},
+		},
+		{
+			order: SortByASN,
+			expected: []string{"dev.example.com", "www.example.com", "example.com",
+				"api.dev.example.com", "b.example.com", "mail.example.org"},
+		},
+		{
+			order: SortByCount,
+			expected: []string{"dev.example.com", "www.example.com", "api.dev.example.com",
+				"b.example.com", "example.com", "mail.example.org"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.order, func(t *testing.T) {
+			names := testSortOutput()
+			SortOutput(names, tc.order)
+			assert.Equal(t, tc.expected, outputNames(names))
+
+			// The result must not depend on the order of the input
+			reversed := testSortOutput()
+			for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+				reversed[i], reversed[j] = reversed[j], reversed[i]
+			}
+			SortOutput(reversed, tc.order)
+			assert.Equal(t, tc.expected, outputNames(reversed))
+		})
+	}
+}
+
+func TestSortAddresses(t *testing.T) {
+	names := testSortOutput()
+	SortOutput(names, SortByName)
+
+	var addrs []string
+	for _, n := range names {
+		if n.Name == "dev.example.com" || n.Name == "www.example.com" {
+			for _, a := range n.Addresses {
+				addrs = append(addrs, a.Address.String())
+			}
+		}
+	}
+	assert.Equal(t, []string{"8.8.4.4", "10.0.0.1", "10.0.0.2", "104.16.1.1", "2606:4700::1"}, addrs)
+}
+
+func TestSortNames(t *testing.T) {
+	names := []string{"www.example.com", "a.example.org", "example.com", "api.example.com", "a.b.example.com"}
+
+	SortNames(names, SortByName)
+	assert.Equal(t, []string{"a.b.example.com", "a.example.org", "api.example.com", "example.com", "www.example.com"}, names)
+
+	SortNames(names, SortByRDNS)
+	assert.Equal(t, []string{"example.com", "api.example.com", "a.b.example.com", "www.example.com", "a.example.org"}, names)
+}
+
+func TestCompareReverseDNS(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "example.com", b: "example.com", expected: 0},
+		{a: "Example.COM.", b: "example.com", expected: 0},
+		{a: "example.com", b: "www.example.com", expected: -1},
+		{a: "www.example.com", b: "api.example.com", expected: 1},
+		{a: "z.example.com", b: "a.example.org", expected: -1},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, CompareReverseDNS(tc.a, tc.b), tc.a+" "+tc.b)
+	}
+}
+
+func TestValidSortOrder(t *testing.T) {
+	for _, order := range SortOrders {
+		assert.True(t, ValidSortOrder(order))
+	}
+	assert.False(t, ValidSortOrder("random"))
+}
@@ -0,0 +1,108 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	oam "github.com/owasp-amass/open-asset-model"
+	oamcert "github.com/owasp-amass/open-asset-model/certificate"
+	"github.com/owasp-amass/open-asset-model/contact"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/fingerprint"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/org"
+	"github.com/owasp-amass/open-asset-model/people"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+	"github.com/owasp-amass/open-asset-model/service"
+	"github.com/owasp-amass/open-asset-model/url"
+)
+
+// ParseAsset builds the OAM asset described by a 'Type:key' string, such as 'IPAddress:1.2.3.4'.
+// The returned asset only has the fields used to find it in the graph populated.
+func ParseAsset(spec string) (oam.Asset, error) {
+	atype, key, found := strings.Cut(strings.TrimSpace(spec), ":")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return nil, fmt.Errorf("%s is not in the correct format: Type:key", spec)
+	}
+
+	var t oam.AssetType
+	for _, at := range oam.AssetList {
+		if strings.EqualFold(string(at), strings.TrimSpace(atype)) {
+			t = at
+			break
+		}
+	}
+
+	switch t {
+	case oam.FQDN:
+		return &domain.FQDN{Name: strings.ToLower(key)}, nil
+	case oam.IPAddress:
+		ip, err := netip.ParseAddr(key)
+		if err != nil {
+			return nil, err
+		}
+
+		addrtype := "IPv4"
+		if ip.Is6() {
+			addrtype = "IPv6"
+		}
+		return &network.IPAddress{Address: ip, Type: addrtype}, nil
+	case oam.Netblock:
+		prefix, err := netip.ParsePrefix(key)
+		if err != nil {
+			return nil, err
+		}
+
+		nbtype := "IPv4"
+		if prefix.Addr().Is6() {
+			nbtype = "IPv6"
+		}
+		return &network.Netblock{CIDR: prefix.Masked(), Type: nbtype}, nil
+	case oam.AutonomousSystem:
+		num, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(key), "AS"))
+		if err != nil {
+			return nil, err
+		}
+		return &network.AutonomousSystem{Number: num}, nil
+	case oam.SocketAddress:
+		addr, err := netip.ParseAddrPort(key)
+		if err != nil {
+			return nil, err
+		}
+		return &network.SocketAddress{Address: addr, IPAddress: addr.Addr(), Port: int(addr.Port())}, nil
+	case oam.NetworkEndpoint:
+		return &domain.NetworkEndpoint{Address: key}, nil
+	case oam.EmailAddress:
+		return &contact.EmailAddress{Address: strings.ToLower(key)}, nil
+	case oam.Phone:
+		return &contact.Phone{Raw: key}, nil
+	case oam.Location:
+		return &contact.Location{Address: key}, nil
+	case oam.Organization:
+		return &org.Organization{Name: key}, nil
+	case oam.Person:
+		return &people.Person{FullName: key}, nil
+	case oam.TLSCertificate:
+		return &oamcert.TLSCertificate{SerialNumber: key}, nil
+	case oam.Fingerprint:
+		return &fingerprint.Fingerprint{Value: key}, nil
+	case oam.URL:
+		return &url.URL{Raw: key}, nil
+	case oam.DomainRecord:
+		return &oamreg.DomainRecord{Domain: strings.ToLower(key)}, nil
+	case oam.AutnumRecord:
+		return &oamreg.AutnumRecord{Handle: key}, nil
+	case oam.IPNetRecord:
+		return &oamreg.IPNetRecord{Handle: key}, nil
+	case oam.Service:
+		return &service.Service{Identifier: key}, nil
+	}
+	return nil, fmt.Errorf("%s is not a supported asset type", atype)
+}
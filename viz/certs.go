@@ -0,0 +1,268 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/caffix/stringset"
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	oam "github.com/owasp-amass/open-asset-model"
+	oamcert "github.com/owasp-amass/open-asset-model/certificate"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+)
+
+// The relations followed backwards from a certificate to the hosts presenting it
+var presentingRels = []string{"certificate", "service", "port"}
+
+// CertificateInfo describes a TLS certificate within the scope and the hosts presenting it.
+type CertificateInfo struct {
+	SerialNumber string    `json:"serial_number"`
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	SANs         []string  `json:"sans"`
+	// Hosts are the network endpoints and socket addresses presenting the certificate
+	Hosts        []string `json:"hosts"`
+	Expired      bool     `json:"expired"`
+	ExpiringSoon bool     `json:"expiring_soon"`
+	SelfSigned   bool     `json:"self_signed"`
+	// OutOfScopeSANs are the SAN names that do not belong to the provided domains
+	OutOfScopeSANs []string `json:"out_of_scope_sans"`
+}
+
+// CertificateInventory returns the certificates issued for or presented by names within the scope.
+// Certificates expiring within the window after now are flagged as expiring soon.
+func CertificateInventory(g *graph.Graph, domains []string, since, now time.Time, window time.Duration) []*CertificateInfo {
+	var results []*CertificateInfo
+
+	assets, err := g.DB.FindByType(oam.TLSCertificate, since)
+	if err != nil {
+		return results
+	}
+
+	for _, a := range assets {
+		c, ok := a.Asset.(*oamcert.TLSCertificate)
+		if !ok {
+			continue
+		}
+
+		info := &CertificateInfo{
+			SerialNumber:   c.SerialNumber,
+			Subject:        c.SubjectCommonName,
+			Issuer:         c.IssuerCommonName,
+			SANs:           certificateSANs(g, a, since),
+			Hosts:          certificateHosts(g, a, since),
+			OutOfScopeSANs: []string{},
+		}
+		if !certificateInScope(g, info, domains, since) {
+			continue
+		}
+
+		info.NotBefore, _ = time.Parse(time.RFC3339, c.NotBefore)
+		info.NotAfter, _ = time.Parse(time.RFC3339, c.NotAfter)
+		info.Expired, info.ExpiringSoon = ExpiryStatus(info.NotAfter, now, window)
+		info.SelfSigned = SelfSignedCertificate(c)
+
+		for _, san := range info.SANs {
+			if net.ParseIP(san) == nil && !requests.DomainNameInScope(strings.TrimPrefix(san, "*."), domains) {
+				info.OutOfScopeSANs = append(info.OutOfScopeSANs, san)
+			}
+		}
+		results = append(results, info)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].NotAfter.Equal(results[j].NotAfter) {
+			return results[i].NotAfter.Before(results[j].NotAfter)
+		}
+		return results[i].SerialNumber < results[j].SerialNumber
+	})
+	return results
+}
+
+// ExpiryStatus checks if a certificate or registration has expired at now, or expires within the window
+// after now. Both are false when the expiration time is unknown.
+func ExpiryStatus(notAfter, now time.Time, window time.Duration) (expired bool, expiring bool) {
+	if notAfter.IsZero() {
+		return false, false
+	}
+
+	expired = now.After(notAfter)
+	return expired, !expired && window > 0 && now.Add(window).After(notAfter)
+}
+
+// SelfSignedCertificate decides on the key identifiers when both are present, and otherwise
+// requires a subject common name that matches the issuer common name.
+func SelfSignedCertificate(c *oamcert.TLSCertificate) bool {
+	if c.SubjectKeyID != "" && c.AuthorityKeyID != "" {
+		return strings.EqualFold(c.SubjectKeyID, c.AuthorityKeyID)
+	}
+	return c.SubjectCommonName != "" && strings.EqualFold(c.SubjectCommonName, c.IssuerCommonName)
+}
+
+func certificateInScope(g *graph.Graph, info *CertificateInfo, domains []string, since time.Time) bool {
+	if requests.DomainNameInScope(strings.TrimPrefix(info.Subject, "*."), domains) {
+		return true
+	}
+	for _, san := range info.SANs {
+		if requests.DomainNameInScope(strings.TrimPrefix(san, "*."), domains) {
+			return true
+		}
+	}
+	for _, host := range info.Hosts {
+		h, _, err := net.SplitHostPort(host)
+		if err != nil {
+			continue
+		}
+
+		if addr, err := netip.ParseAddr(h); err == nil {
+			if AddressInScope(g, addr, domains, since) {
+				return true
+			}
+		} else if requests.DomainNameInScope(h, domains) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddressInScope checks if a name within the scope resolves to the address, directly or through CNAME records.
+func AddressInScope(g *graph.Graph, addr netip.Addr, domains []string, since time.Time) bool {
+	assets, err := g.DB.FindByContent(&network.IPAddress{Address: addr}, since)
+	if err != nil {
+		return false
+	}
+
+	var queue []*types.Asset
+	for _, a := range assets {
+		queue = append(queue, requests.IncomingAssets(g, a, since, "a_record", "aaaa_record")...)
+	}
+
+	seen := make(map[string]struct{})
+	for len(queue) > 0 {
+		a := queue[0]
+		queue = queue[1:]
+
+		if _, found := seen[a.ID]; found {
+			continue
+		}
+		seen[a.ID] = struct{}{}
+
+		if n, ok := a.Asset.(*domain.FQDN); ok {
+			if requests.DomainNameInScope(n.Name, domains) {
+				return true
+			}
+			queue = append(queue, requests.IncomingAssets(g, a, since, "cname_record")...)
+		}
+	}
+	return false
+}
+
+// ScopeAddresses returns the addresses that the names within the scope resolve to, directly or through CNAME records.
+func ScopeAddresses(g *graph.Graph, domains []string, since time.Time) []*types.Asset {
+	var results []*types.Asset
+	if len(domains) == 0 {
+		return results
+	}
+
+	var fqdns []oam.Asset
+	for _, d := range domains {
+		fqdns = append(fqdns, &domain.FQDN{Name: d})
+	}
+
+	assets, err := g.DB.FindByScope(fqdns, since)
+	if err != nil {
+		return results
+	}
+
+	seen := make(map[string]struct{})
+	for _, a := range assets {
+		if n, ok := a.Asset.(*domain.FQDN); !ok || !requests.DomainNameInScope(n.Name, domains) {
+			continue
+		}
+
+		_, final := followCNAMEChain(g, a, since)
+		for _, addr := range requests.OutgoingAssets(g, final, since, "a_record", "aaaa_record") {
+			if _, found := seen[addr.ID]; !found {
+				seen[addr.ID] = struct{}{}
+				results = append(results, addr)
+			}
+		}
+	}
+	return results
+}
+
+func certificateSANs(g *graph.Graph, cert *types.Asset, since time.Time) []string {
+	sans := stringset.New()
+	defer sans.Close()
+
+	for _, a := range requests.OutgoingAssets(g, cert, since, "san_dns_name", "san_ip_address") {
+		switch v := a.Asset.(type) {
+		case *domain.FQDN:
+			sans.Insert(v.Name)
+		case *network.IPAddress:
+			sans.Insert(v.Address.String())
+		}
+	}
+
+	list := sans.Slice()
+	sort.Strings(list)
+	return list
+}
+
+// certificateHosts returns the addresses of the endpoints presenting the certificate.
+func certificateHosts(g *graph.Graph, cert *types.Asset, since time.Time) []string {
+	hosts := stringset.New()
+	defer hosts.Close()
+
+	for _, a := range PresentingHosts(g, cert, since, presentingRels...) {
+		switch v := a.Asset.(type) {
+		case *domain.NetworkEndpoint:
+			hosts.Insert(v.Address)
+		case *network.SocketAddress:
+			hosts.Insert(v.Address.String())
+		}
+	}
+
+	list := hosts.Slice()
+	sort.Strings(list)
+	return list
+}
+
+// PresentingHosts runs the provided relations in reverse from the asset to find the network endpoints
+// and socket addresses presenting it.
+func PresentingHosts(g *graph.Graph, asset *types.Asset, since time.Time, rtypes ...string) []*types.Asset {
+	var hosts []*types.Asset
+
+	queue := []*types.Asset{asset}
+	seen := make(map[string]struct{})
+	for len(queue) > 0 {
+		a := queue[0]
+		queue = queue[1:]
+
+		if _, found := seen[a.ID]; found {
+			continue
+		}
+		seen[a.ID] = struct{}{}
+
+		switch a.Asset.(type) {
+		case *domain.NetworkEndpoint, *network.SocketAddress:
+			hosts = append(hosts, a)
+			continue
+		}
+
+		queue = append(queue, requests.IncomingAssets(g, a, since, rtypes...)...)
+	}
+	return hosts
+}
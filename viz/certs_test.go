@@ -0,0 +1,182 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	oamcert "github.com/owasp-amass/open-asset-model/certificate"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertificateInventory(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// An in-scope alias of a provider edge address, and a name outside the scope
+	_, err := g.UpsertA(ctx, "www.certtest.domain", "93.184.216.120")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(ctx, "alias.certtest.domain", "edge.certtest-provider.net")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "edge.certtest-provider.net", "93.184.216.121")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "www.certtest-unrelated.org", "93.184.216.122")
+	assert.Nil(t, err)
+
+	newCert := func(c *oamcert.TLSCertificate, sans ...string) *types.Asset {
+		a, err := g.DB.Create(nil, "", c)
+		assert.Nil(t, err)
+
+		for _, san := range sans {
+			if addr, err := netip.ParseAddr(san); err == nil {
+				_, err = g.DB.Create(a, "san_ip_address", &network.IPAddress{Address: addr, Type: "IPv4"})
+				assert.Nil(t, err)
+			} else {
+				_, err = g.DB.Create(a, "san_dns_name", &domain.FQDN{Name: san})
+				assert.Nil(t, err)
+			}
+		}
+		return a
+	}
+	present := func(host, cert *types.Asset, id string) {
+		svc, err := g.DB.Create(host, "service", &service.Service{Identifier: id})
+		assert.Nil(t, err)
+		_, err = g.DB.Link(svc, "certificate", cert)
+		assert.Nil(t, err)
+	}
+
+	// An expired certificate issued by a CA, with a SAN outside the scope
+	newCert(&oamcert.TLSCertificate{SerialNumber: "certtest01", SubjectCommonName: "www.certtest.domain",
+		IssuerCommonName: "Certtest CA", NotAfter: now.Add(-24 * time.Hour).Format(time.RFC3339),
+		AuthorityKeyID: "ca", SubjectKeyID: "leaf"}, "www.certtest.domain", "shop.certtest-other.com", "93.184.216.120")
+
+	// A provider certificate presented on the address an in-scope alias resolves to
+	edge := newCert(&oamcert.TLSCertificate{SerialNumber: "certtest02", SubjectCommonName: "edge.certtest-provider.net",
+		IssuerCommonName: "Certtest Provider CA", NotAfter: now.Add(7 * 24 * time.Hour).Format(time.RFC3339),
+		AuthorityKeyID: "K1", SubjectKeyID: "k1"})
+	ip := netip.MustParseAddr("93.184.216.121")
+	sa, err := g.DB.Create(nil, "", &network.SocketAddress{
+		Address: netip.AddrPortFrom(ip, 443), IPAddress: ip, Port: 443, Protocol: "https"})
+	assert.Nil(t, err)
+	present(sa, edge, "certtest-svc02")
+
+	// A certificate without common names or key identifiers, only covering an in-scope SAN
+	newCert(&oamcert.TLSCertificate{SerialNumber: "certtest03",
+		NotAfter: now.Add(365 * 24 * time.Hour).Format(time.RFC3339)}, "api.certtest.domain")
+
+	// A device certificate presented by an in-scope endpoint, without a validity window
+	device := newCert(&oamcert.TLSCertificate{SerialNumber: "certtest04",
+		SubjectCommonName: "Certtest Device", IssuerCommonName: "Certtest Device"})
+	www, err := g.DB.FindByContent(&domain.FQDN{Name: "www.certtest.domain"}, time.Time{})
+	if assert.Nil(t, err) && assert.NotEmpty(t, www) {
+		ep, err := g.DB.Create(www[0], "port", &domain.NetworkEndpoint{
+			Address: "www.certtest.domain:8443", Name: "www.certtest.domain", Port: 8443, Protocol: "https"})
+		assert.Nil(t, err)
+		present(ep, device, "certtest-svc04")
+	}
+
+	// A certificate issued for and presented by names outside the scope
+	other := newCert(&oamcert.TLSCertificate{SerialNumber: "certtest05", SubjectCommonName: "www.certtest-unrelated.org",
+		IssuerCommonName: "Certtest CA"}, "www.certtest-unrelated.org")
+	unrelated, err := g.DB.FindByContent(&domain.FQDN{Name: "www.certtest-unrelated.org"}, time.Time{})
+	if assert.Nil(t, err) && assert.NotEmpty(t, unrelated) {
+		ep, err := g.DB.Create(unrelated[0], "port", &domain.NetworkEndpoint{
+			Address: "www.certtest-unrelated.org:443", Name: "www.certtest-unrelated.org", Port: 443, Protocol: "https"})
+		assert.Nil(t, err)
+		present(ep, other, "certtest-svc05")
+	}
+
+	certs := CertificateInventory(g, []string{"certtest.domain"}, time.Time{}, now, 30*24*time.Hour)
+
+	if assert.Len(t, certs, 4) {
+		c := certs[0]
+		assert.Equal(t, "certtest04", c.SerialNumber)
+		assert.True(t, c.NotAfter.IsZero())
+		assert.Equal(t, []string{"www.certtest.domain:8443"}, c.Hosts)
+		assert.True(t, c.SelfSigned)
+		assert.False(t, c.Expired || c.ExpiringSoon)
+
+		c = certs[1]
+		assert.Equal(t, "certtest01", c.SerialNumber)
+		assert.Equal(t, []string{"93.184.216.120", "shop.certtest-other.com", "www.certtest.domain"}, c.SANs)
+		assert.Equal(t, []string{"shop.certtest-other.com"}, c.OutOfScopeSANs)
+		assert.Empty(t, c.Hosts)
+		assert.True(t, c.Expired)
+		assert.False(t, c.ExpiringSoon || c.SelfSigned)
+
+		c = certs[2]
+		assert.Equal(t, "certtest02", c.SerialNumber)
+		assert.Equal(t, []string{"93.184.216.121:443"}, c.Hosts)
+		assert.Empty(t, c.SANs)
+		assert.True(t, c.ExpiringSoon)
+		assert.True(t, c.SelfSigned)
+
+		c = certs[3]
+		assert.Equal(t, "certtest03", c.SerialNumber)
+		assert.Equal(t, []string{"api.certtest.domain"}, c.SANs)
+		assert.False(t, c.Expired || c.ExpiringSoon || c.SelfSigned)
+	}
+
+	// Addresses are only within the scope through names that resolve to them
+	assert.True(t, AddressInScope(g, netip.MustParseAddr("93.184.216.121"), []string{"certtest.domain"}, time.Time{}))
+	assert.False(t, AddressInScope(g, netip.MustParseAddr("93.184.216.122"), []string{"certtest.domain"}, time.Time{}))
+
+	assert.Empty(t, CertificateInventory(g, []string{"certtest-missing.domain"}, time.Time{}, now, 0))
+}
+
+func TestSelfSignedCertificate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cert     *oamcert.TLSCertificate
+		expected bool
+	}{
+		{"matching key identifiers", &oamcert.TLSCertificate{SubjectKeyID: "ab:cd", AuthorityKeyID: "AB:CD"}, true},
+		{"key identifiers decide over the names", &oamcert.TLSCertificate{SubjectCommonName: "host",
+			IssuerCommonName: "host", SubjectKeyID: "ab", AuthorityKeyID: "cd"}, false},
+		{"matching common names", &oamcert.TLSCertificate{SubjectCommonName: "Host", IssuerCommonName: "host"}, true},
+		{"matching names with one key identifier", &oamcert.TLSCertificate{SubjectCommonName: "host",
+			IssuerCommonName: "host", SubjectKeyID: "ab"}, true},
+		{"issued by a CA", &oamcert.TLSCertificate{SubjectCommonName: "host", IssuerCommonName: "CA"}, false},
+		{"empty common names", &oamcert.TLSCertificate{}, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, SelfSignedCertificate(test.cert), test.name)
+	}
+}
+
+func TestExpiryStatus(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name              string
+		notAfter          time.Time
+		window            time.Duration
+		expired, expiring bool
+	}{
+		{"unknown expiration", time.Time{}, week, false, false},
+		{"expired", now.Add(-time.Hour), week, true, false},
+		{"within the window", now.Add(24 * time.Hour), week, false, true},
+		{"after the window", now.Add(2 * week), week, false, false},
+		{"without a window", now.Add(24 * time.Hour), 0, false, false},
+	}
+
+	for _, test := range tests {
+		expired, expiring := ExpiryStatus(test.notAfter, now, test.window)
+
+		assert.Equal(t, test.expired, expired, test.name)
+		assert.Equal(t, test.expiring, expiring, test.name)
+	}
+}
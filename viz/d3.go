@@ -44,7 +44,7 @@ const d3Template = `
 var graph = {
     nodes: [
     {{ range .Nodes }}
-        {id: {{.ID }}, num: {{ .Num }}, label: "{{ .Label }}", color: "{{ .Color }}" },
+        {id: {{.ID }}, num: {{ .Num }}, label: "{{ .Label }}", color: "{{ .Color }}", indegree: {{ .InDegree }}, outdegree: {{ .OutDegree }}, betweenness: {{ .Betweenness }}, component: {{ .Component }}, community: {{ .Community }} },
     {{ end }}
     ],
     edges: [
@@ -178,7 +178,12 @@ function update() {
             .style('opacity', 0.8)
             .style('top', transform.applyY(closeNode.y) + 5 + 'px')
             .style('left', transform.applyX(closeNode.x) + 5 + 'px')
-            .html(closeNode.label);
+            .html(closeNode.label +
+                "<br>In-Degree: " + closeNode.indegree +
+                "<br>Out-Degree: " + closeNode.outdegree +
+                "<br>Betweenness: " + closeNode.betweenness.toFixed(4) +
+                "<br>Component: " + closeNode.component +
+                "<br>Community: " + closeNode.community);
     }  else {
         d3.select('#tooltip')
             .style('opacity', 0);
@@ -294,10 +299,15 @@ type d3Edge struct {
 }
 
 type d3Node struct {
-	ID    int
-	Num   int
-	Label string
-	Color string
+	ID          int
+	Num         int
+	Label       string
+	Color       string
+	InDegree    int
+	OutDegree   int
+	Betweenness float64
+	Component   int
+	Community   int
 }
 
 type d3Graph struct {
@@ -334,11 +344,17 @@ func WriteD3Data(output io.Writer, nodes []Node, edges []Edge) error {
 
 	graph := &d3Graph{Name: "OWASP Amass - Attack Surface Mapping"}
 
+	metrics := AnalyzeGraph(nodes, edges)
 	for idx, node := range nodes {
 		graph.Nodes = append(graph.Nodes, d3Node{
-			ID:    idx,
-			Label: node.Title,
-			Color: colors[node.Type],
+			ID:          idx,
+			Label:       node.Title,
+			Color:       colors[node.Type],
+			InDegree:    metrics[idx].InDegree,
+			OutDegree:   metrics[idx].OutDegree,
+			Betweenness: metrics[idx].Betweenness,
+			Component:   metrics[idx].Component,
+			Community:   metrics[idx].Community,
 		})
 	}
 
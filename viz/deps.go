@@ -0,0 +1,286 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/contact"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/org"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Dependency is an out-of-scope domain, organization or autonomous system relied on by in-scope names.
+type Dependency struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Relations are the relations that lead from the in-scope names to the dependency
+	Relations []string `json:"relations"`
+	// Names are the in-scope names relying on the dependency
+	Names []string `json:"names"`
+}
+
+// DependencyReport contains the third-party infrastructure that the in-scope names rely on.
+type DependencyReport struct {
+	Domains       []*Dependency `json:"domains"`
+	Organizations []*Dependency `json:"organizations"`
+	ASNs          []*Dependency `json:"asns"`
+}
+
+type dependencySet map[string]*dependencyEntry
+
+type dependencyEntry struct {
+	desc      string
+	relations map[string]struct{}
+	names     map[string]struct{}
+}
+
+func (s dependencySet) add(key, desc, relation, name string) {
+	e, found := s[key]
+	if !found {
+		e = &dependencyEntry{
+			relations: make(map[string]struct{}),
+			names:     make(map[string]struct{}),
+		}
+		s[key] = e
+	}
+
+	if e.desc == "" {
+		e.desc = desc
+	}
+	e.relations[relation] = struct{}{}
+	e.names[name] = struct{}{}
+}
+
+// list returns the dependencies ordered by the number of in-scope names relying on them.
+func (s dependencySet) list() []*Dependency {
+	deps := make([]*Dependency, 0, len(s))
+
+	for key, e := range s {
+		d := &Dependency{
+			Name:        key,
+			Description: e.desc,
+			Relations:   make([]string, 0, len(e.relations)),
+			Names:       make([]string, 0, len(e.names)),
+		}
+		for rel := range e.relations {
+			d.Relations = append(d.Relations, rel)
+		}
+		for n := range e.names {
+			d.Names = append(d.Names, n)
+		}
+
+		sort.Strings(d.Relations)
+		sort.Strings(d.Names)
+		deps = append(deps, d)
+	}
+
+	sort.Slice(deps, func(i, j int) bool {
+		if len(deps[i].Names) != len(deps[j].Names) {
+			return len(deps[i].Names) > len(deps[j].Names)
+		}
+		return deps[i].Name < deps[j].Name
+	})
+	return deps
+}
+
+// Dependencies returns the out-of-scope domains, organizations and autonomous systems that the
+// in-scope names rely on through their CNAME, NS, MX and SRV records and the addresses they resolve to.
+func Dependencies(domains []string, since time.Time, g *graph.Graph) *DependencyReport {
+	report := &DependencyReport{
+		Domains:       []*Dependency{},
+		Organizations: []*Dependency{},
+		ASNs:          []*Dependency{},
+	}
+	if len(domains) == 0 {
+		return report
+	}
+
+	var fqdns []oam.Asset
+	for _, d := range domains {
+		fqdns = append(fqdns, &domain.FQDN{Name: d})
+	}
+
+	if !since.IsZero() {
+		since = since.UTC()
+	}
+
+	assets, err := g.DB.FindByScope(fqdns, since)
+	if err != nil {
+		return report
+	}
+
+	doms := make(dependencySet)
+	orgs := make(dependencySet)
+	asns := make(dependencySet)
+	own := targetOrganizations(g, domains, since)
+	seen := make(map[string]struct{})
+	for _, a := range assets {
+		n, ok := a.Asset.(*domain.FQDN)
+		if !ok || !requests.DomainNameInScope(n.Name, domains) {
+			continue
+		}
+		if _, found := seen[n.Name]; found {
+			continue
+		}
+		seen[n.Name] = struct{}{}
+
+		chain, final := followCNAMEChain(g, a, since)
+		for _, link := range chain {
+			if !requests.DomainNameInScope(link.Name, domains) {
+				addDomainDependency(g, doms, orgs, link.Name, "cname_record", n.Name, since)
+			}
+		}
+
+		for _, rtype := range []string{"ns_record", "mx_record", "srv_record"} {
+			for _, target := range requests.OutgoingAssets(g, a, since, rtype) {
+				if t, ok := target.Asset.(*domain.FQDN); ok && !requests.DomainNameInScope(t.Name, domains) {
+					addDomainDependency(g, doms, orgs, t.Name, rtype, n.Name, since)
+				}
+			}
+		}
+
+		for _, rtype := range []string{"a_record", "aaaa_record"} {
+			for _, addr := range requests.OutgoingAssets(g, final, since, rtype) {
+				addAddressDependencies(g, asns, orgs, own, addr, n.Name, since)
+			}
+		}
+	}
+
+	// The organizations registering the provided domains are the target, not a third party
+	for key := range orgs {
+		if _, found := own[strings.ToLower(key)]; found {
+			delete(orgs, key)
+		}
+	}
+
+	report.Domains = doms.list()
+	report.Organizations = orgs.list()
+	report.ASNs = asns.list()
+	return report
+}
+
+func addDomainDependency(g *graph.Graph, doms, orgs dependencySet, name, relation, inscope string, since time.Time) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+	registered, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		registered = name
+	}
+	doms.add(registered, "", relation, inscope)
+
+	if assets, err := g.DB.FindByContent(&domain.FQDN{Name: registered}, since); err == nil {
+		for _, a := range assets {
+			for _, reg := range requests.OutgoingAssets(g, a, since, "registration") {
+				if o := registrantOrganization(g, reg, "registrant_contact", since); o != "" {
+					orgs.add(o, "", relation, inscope)
+				}
+			}
+		}
+	}
+}
+
+// addAddressDependencies adds the autonomous systems announcing the address and their holders,
+// leaving out the autonomous systems held by the organizations of the target.
+func addAddressDependencies(g *graph.Graph, asns, orgs dependencySet, own map[string]struct{}, addr *types.Asset, inscope string, since time.Time) {
+	rels, err := g.DB.IncomingRelations(addr, since, "contains")
+	if err != nil {
+		return
+	}
+
+	for _, rel := range rels {
+		nb, err := g.DB.FindById(rel.FromAsset.ID, since)
+		if err != nil || nb == nil {
+			continue
+		}
+
+		arels, err := g.DB.IncomingRelations(nb, since, "announces")
+		if err != nil {
+			continue
+		}
+
+		for _, arel := range arels {
+			a, err := g.DB.FindById(arel.FromAsset.ID, since)
+			if err != nil || a == nil {
+				continue
+			}
+
+			as, ok := a.Asset.(*network.AutonomousSystem)
+			if !ok {
+				continue
+			}
+
+			var desc, holder string
+			for _, reg := range requests.OutgoingAssets(g, a, since, "registration") {
+				if autnum, ok := reg.Asset.(*oamreg.AutnumRecord); ok {
+					desc = autnum.Handle + " - " + autnum.Name
+					holder = autnum.Name
+
+					if o := registrantOrganization(g, reg, "registrant", since); o != "" {
+						holder = o
+					}
+					break
+				}
+			}
+
+			if _, found := own[strings.ToLower(holder)]; found {
+				continue
+			}
+
+			asns.add(strconv.Itoa(as.Number), desc, "announces", inscope)
+			if holder != "" {
+				orgs.add(holder, "", "announces", inscope)
+			}
+		}
+	}
+}
+
+// targetOrganizations returns the lowercase names of the registrant organizations of the provided domains,
+// leaving out the details hidden by privacy services.
+func targetOrganizations(g *graph.Graph, domains []string, since time.Time) map[string]struct{} {
+	own := make(map[string]struct{})
+
+	for _, d := range domains {
+		assets, err := g.DB.FindByContent(&domain.FQDN{Name: strings.ToLower(strings.TrimSuffix(d, "."))}, since)
+		if err != nil {
+			continue
+		}
+
+		for _, a := range assets {
+			for _, reg := range requests.OutgoingAssets(g, a, since, "registration") {
+				if o := registrantOrganization(g, reg, "registrant_contact", since); o != "" && !PrivacyProtected(o) {
+					own[strings.ToLower(o)] = struct{}{}
+				}
+			}
+		}
+	}
+	return own
+}
+
+// registrantOrganization returns the name of the organization in the registrant contact of the registration record.
+func registrantOrganization(g *graph.Graph, record *types.Asset, relation string, since time.Time) string {
+	for _, c := range requests.OutgoingAssets(g, record, since, relation) {
+		if _, ok := c.Asset.(*contact.ContactRecord); !ok {
+			continue
+		}
+
+		for _, o := range requests.OutgoingAssets(g, c, since, "organization") {
+			if organization, ok := o.Asset.(*org.Organization); ok && organization.Name != "" {
+				return organization.Name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,165 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/open-asset-model/contact"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/org"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependencies(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+
+	_, err := g.UpsertCNAME(ctx, "www.depstest.domain", "depstest.cdn-vendor.net")
+	assert.Nil(t, err)
+	ip, err := g.UpsertA(ctx, "depstest.cdn-vendor.net", "203.0.113.10")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(ctx, "static.depstest.domain", "static-depstest.cdn-vendor.net")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "static-depstest.cdn-vendor.net", "203.0.113.10")
+	assert.Nil(t, err)
+	_, err = g.UpsertNS(ctx, "www.depstest.domain", "ns1.dns-vendor.org")
+	assert.Nil(t, err)
+	_, err = g.UpsertNS(ctx, "static.depstest.domain", "ns1.depstest.domain")
+	assert.Nil(t, err)
+	_, err = g.UpsertMX(ctx, "mail.depstest.domain", "inbound.mail-vendor.com")
+	assert.Nil(t, err)
+
+	// The address is announced by an autonomous system with a registrant organization
+	nb, err := g.DB.Create(nil, "", &network.Netblock{CIDR: netip.MustParsePrefix("203.0.113.0/24"), Type: "IPv4"})
+	assert.Nil(t, err)
+	_, err = g.DB.Link(nb, "contains", ip)
+	assert.Nil(t, err)
+	as, err := g.DB.Create(nil, "", &network.AutonomousSystem{Number: 64511})
+	assert.Nil(t, err)
+	_, err = g.DB.Link(as, "announces", nb)
+	assert.Nil(t, err)
+	autnum, err := g.DB.Create(as, "registration", &oamreg.AutnumRecord{Number: 64511, Handle: "AS64511", Name: "CDNVENDOR"})
+	assert.Nil(t, err)
+	cr, err := g.DB.Create(autnum, "registrant", &contact.ContactRecord{DiscoveredAt: "https://rdap.example/autnum/64511"})
+	assert.Nil(t, err)
+	_, err = g.DB.Create(cr, "organization", &org.Organization{Name: "CDN Vendor Inc"})
+	assert.Nil(t, err)
+
+	// Place the discovered names within the scope of the root domain
+	root, err := g.DB.Create(nil, "", &domain.FQDN{Name: "depstest.domain"})
+	assert.Nil(t, err)
+	for _, sub := range []string{"www", "static", "mail", "ns1"} {
+		assets, err := g.DB.FindByContent(&domain.FQDN{Name: sub + ".depstest.domain"}, time.Time{})
+		if assert.Nil(t, err) && assert.NotEmpty(t, assets) {
+			_, err = g.DB.Link(root, "node", assets[0])
+			assert.Nil(t, err)
+		}
+	}
+
+	report := Dependencies([]string{"depstest.domain"}, time.Time{}, g)
+
+	if assert.Len(t, report.Domains, 3) {
+		d := report.Domains[0]
+		assert.Equal(t, "cdn-vendor.net", d.Name)
+		assert.Equal(t, []string{"cname_record"}, d.Relations)
+		assert.Equal(t, []string{"static.depstest.domain", "www.depstest.domain"}, d.Names)
+
+		assert.Equal(t, "dns-vendor.org", report.Domains[1].Name)
+		assert.Equal(t, []string{"ns_record"}, report.Domains[1].Relations)
+		assert.Equal(t, "mail-vendor.com", report.Domains[2].Name)
+		assert.Equal(t, []string{"mail.depstest.domain"}, report.Domains[2].Names)
+	}
+	if assert.Len(t, report.ASNs, 1) {
+		assert.Equal(t, "64511", report.ASNs[0].Name)
+		assert.Equal(t, "AS64511 - CDNVENDOR", report.ASNs[0].Description)
+		assert.Len(t, report.ASNs[0].Names, 2)
+	}
+	if assert.Len(t, report.Organizations, 1) {
+		assert.Equal(t, "CDN Vendor Inc", report.Organizations[0].Name)
+		assert.Len(t, report.Organizations[0].Names, 2)
+	}
+
+	empty := Dependencies([]string{}, time.Time{}, g)
+	assert.Empty(t, empty.Domains)
+	assert.Empty(t, empty.ASNs)
+}
+
+func TestDependenciesLeaveOutTarget(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+
+	_, err := g.UpsertA(ctx, "www.depsowntest.domain", "93.184.216.170")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(ctx, "cdn.depsowntest.domain", "depsowntest.cdn-vendor.net")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "depsowntest.cdn-vendor.net", "93.184.217.170")
+	assert.Nil(t, err)
+
+	root, err := g.DB.Create(nil, "", &domain.FQDN{Name: "depsowntest.domain"})
+	assert.Nil(t, err)
+	for _, sub := range []string{"www", "cdn"} {
+		assets, err := g.DB.FindByContent(&domain.FQDN{Name: sub + ".depsowntest.domain"}, time.Time{})
+		if assert.Nil(t, err) && assert.NotEmpty(t, assets) {
+			_, err = g.DB.Link(root, "node", assets[0])
+			assert.Nil(t, err)
+		}
+	}
+
+	registrant := func(record *types.Asset, rel, name string) {
+		cr, err := g.DB.Create(record, rel, &contact.ContactRecord{DiscoveredAt: "https://rdap.example/" + record.ID})
+		assert.Nil(t, err)
+		_, err = g.DB.Create(cr, "organization", &org.Organization{Name: name})
+		assert.Nil(t, err)
+	}
+
+	// The target registers the root domain and holds the autonomous system announcing its own address
+	dr, err := g.DB.Create(root, "registration", &oamreg.DomainRecord{Domain: "depsowntest.domain", Name: "depsowntest.domain"})
+	assert.Nil(t, err)
+	registrant(dr, "registrant_contact", "Depsowntest Corp")
+
+	for num, addr := range map[int]string{64520: "93.184.216.170", 64521: "93.184.217.170"} {
+		ip, err := g.DB.FindByContent(&network.IPAddress{Address: netip.MustParseAddr(addr), Type: "IPv4"}, time.Time{})
+		if !assert.Nil(t, err) || !assert.NotEmpty(t, ip) {
+			continue
+		}
+
+		prefix := netip.PrefixFrom(netip.MustParseAddr(addr), 24).Masked()
+		nb, err := g.DB.Create(nil, "", &network.Netblock{CIDR: prefix, Type: "IPv4"})
+		assert.Nil(t, err)
+		_, err = g.DB.Link(nb, "contains", ip[0])
+		assert.Nil(t, err)
+		as, err := g.DB.Create(nil, "", &network.AutonomousSystem{Number: num})
+		assert.Nil(t, err)
+		_, err = g.DB.Link(as, "announces", nb)
+		assert.Nil(t, err)
+		autnum, err := g.DB.Create(as, "registration", &oamreg.AutnumRecord{Number: num, Handle: "AS" + strconv.Itoa(num)})
+		assert.Nil(t, err)
+
+		holder := "DEPSOWNTEST CORP"
+		if num == 64521 {
+			holder = "Depsowntest CDN Inc"
+		}
+		registrant(autnum, "registrant", holder)
+	}
+
+	report := Dependencies([]string{"depsowntest.domain"}, time.Time{}, g)
+
+	if assert.Len(t, report.ASNs, 1) {
+		assert.Equal(t, "64521", report.ASNs[0].Name)
+		assert.Equal(t, []string{"cdn.depsowntest.domain"}, report.ASNs[0].Names)
+	}
+	if assert.Len(t, report.Organizations, 1) {
+		assert.Equal(t, "Depsowntest CDN Inc", report.Organizations[0].Name)
+	}
+}
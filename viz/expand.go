@@ -0,0 +1,339 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	oam "github.com/owasp-amass/open-asset-model"
+	oamcert "github.com/owasp-amass/open-asset-model/certificate"
+	"github.com/owasp-amass/open-asset-model/contact"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/owasp-amass/open-asset-model/org"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Types of evidence supporting a scope expansion candidate.
+const (
+	EvidenceRegistrant  = "registrant"
+	EvidenceNameServer  = "name_server"
+	EvidenceCertificate = "certificate"
+	EvidenceAddress     = "address"
+)
+
+// Weights given to each piece of evidence when ranking the candidates
+const (
+	strongEvidence = 3
+	mediumEvidence = 2
+	weakEvidence   = 1
+)
+
+// Substrings of the contact details hidden by privacy services
+var privacyProtectedValues = []string{"redacted", "privacy", "withheld", "not disclosed", "data protected", "proxy"}
+
+// ExpansionEvidence is an asset shared by a candidate domain and the current scope.
+type ExpansionEvidence struct {
+	Type string `json:"type"`
+	// Value is the shared asset, such as the name server, certificate serial number or address
+	Value string `json:"value"`
+	// InScope is the name within the scope that shares the asset
+	InScope string `json:"in_scope"`
+	// Name is the name under the candidate domain that shares the asset
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// ExpansionCandidate is a root domain outside the scope that appears to belong to the same target.
+type ExpansionCandidate struct {
+	Domain   string               `json:"domain"`
+	Score    int                  `json:"score"`
+	Evidence []*ExpansionEvidence `json:"evidence"`
+}
+
+type expansionSet map[string]map[string]*ExpansionEvidence
+
+// add records the evidence for the registered domain of the name, once per type and shared value.
+func (s expansionSet) add(domains []string, name string, e *ExpansionEvidence) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSuffix(name, "."), "*."))
+	if name == "" || requests.DomainNameInScope(name, domains) {
+		return
+	}
+
+	root, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		return
+	}
+
+	if _, found := s[root]; !found {
+		s[root] = make(map[string]*ExpansionEvidence)
+	}
+
+	e.Name = name
+	key := e.Type + ":" + e.Value
+	if cur, found := s[root][key]; !found || e.InScope+e.Name < cur.InScope+cur.Name {
+		s[root][key] = e
+	}
+}
+
+func (s expansionSet) candidates() []*ExpansionCandidate {
+	results := make([]*ExpansionCandidate, 0, len(s))
+
+	for root, evidence := range s {
+		c := &ExpansionCandidate{Domain: root}
+
+		// Each type of evidence is counted once, so that the many addresses of a shared
+		// CDN or the name servers of a DNS provider do not outweigh a shared registrant
+		strongest := make(map[string]int)
+		for _, e := range evidence {
+			if e.Weight > strongest[e.Type] {
+				strongest[e.Type] = e.Weight
+			}
+			c.Evidence = append(c.Evidence, e)
+		}
+		for _, w := range strongest {
+			c.Score += w
+		}
+
+		sort.Slice(c.Evidence, func(i, j int) bool {
+			ei, ej := c.Evidence[i], c.Evidence[j]
+
+			if ei.Weight != ej.Weight {
+				return ei.Weight > ej.Weight
+			}
+			if ei.Type != ej.Type {
+				return ei.Type < ej.Type
+			}
+			return ei.Value < ej.Value
+		})
+		results = append(results, c)
+	}
+
+	// Among equal scores, a single strong piece of evidence ranks above several weaker ones
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if wi, wj := results[i].Evidence[0].Weight, results[j].Evidence[0].Weight; wi != wj {
+			return wi > wj
+		}
+		return results[i].Domain < results[j].Domain
+	})
+	return results
+}
+
+// ExpansionCandidates proposes root domains to add to the scope, ranked by the evidence they share with
+// the current domains: registrant contacts, name servers, certificates with overlapping SANs and addresses.
+func ExpansionCandidates(domains []string, since time.Time, g *graph.Graph) []*ExpansionCandidate {
+	if len(domains) == 0 {
+		return []*ExpansionCandidate{}
+	}
+
+	var fqdns []oam.Asset
+	for _, d := range domains {
+		fqdns = append(fqdns, &domain.FQDN{Name: d})
+	}
+
+	if !since.IsZero() {
+		since = since.UTC()
+	}
+
+	set := make(expansionSet)
+	if assets, err := g.DB.FindByScope(fqdns, since); err == nil {
+		seen := make(map[string]struct{})
+
+		for _, a := range assets {
+			n, ok := a.Asset.(*domain.FQDN)
+			if !ok || !requests.DomainNameInScope(n.Name, domains) {
+				continue
+			}
+			if _, found := seen[a.ID]; found {
+				continue
+			}
+			seen[a.ID] = struct{}{}
+
+			sharedNameServers(g, a, n.Name, domains, since, set)
+			sharedAddresses(g, a, n.Name, domains, since, set)
+		}
+	}
+
+	sharedCertificates(g, domains, since, set)
+	sharedRegistrants(g, domains, since, set)
+	return set.candidates()
+}
+
+func sharedNameServers(g *graph.Graph, a *types.Asset, name string, domains []string, since time.Time, set expansionSet) {
+	for _, ns := range requests.OutgoingAssets(g, a, since, "ns_record") {
+		server, ok := ns.Asset.(*domain.FQDN)
+		if !ok {
+			continue
+		}
+
+		// Name servers operated by the target are stronger evidence than a shared DNS provider
+		weight := weakEvidence
+		if requests.DomainNameInScope(server.Name, domains) {
+			weight = strongEvidence
+		}
+
+		for _, other := range requests.IncomingAssets(g, ns, since, "ns_record") {
+			if o, ok := other.Asset.(*domain.FQDN); ok {
+				set.add(domains, o.Name, &ExpansionEvidence{
+					Type:    EvidenceNameServer,
+					Value:   server.Name,
+					InScope: name,
+					Weight:  weight,
+				})
+			}
+		}
+	}
+}
+
+func sharedAddresses(g *graph.Graph, a *types.Asset, name string, domains []string, since time.Time, set expansionSet) {
+	for _, rtype := range []string{"a_record", "aaaa_record"} {
+		for _, addr := range requests.OutgoingAssets(g, a, since, rtype) {
+			ip, ok := addr.Asset.(*network.IPAddress)
+			if !ok {
+				continue
+			}
+			if reserved, _ := requests.IsReservedAddress(ip.Address.String()); reserved {
+				continue
+			}
+
+			for _, other := range requests.IncomingAssets(g, addr, since, rtype) {
+				if o, ok := other.Asset.(*domain.FQDN); ok {
+					set.add(domains, o.Name, &ExpansionEvidence{
+						Type:    EvidenceAddress,
+						Value:   ip.Address.String(),
+						InScope: name,
+						Weight:  mediumEvidence,
+					})
+				}
+			}
+		}
+	}
+}
+
+func sharedCertificates(g *graph.Graph, domains []string, since time.Time, set expansionSet) {
+	certs, err := g.DB.FindByType(oam.TLSCertificate, since)
+	if err != nil {
+		return
+	}
+
+	for _, a := range certs {
+		c, ok := a.Asset.(*oamcert.TLSCertificate)
+		if !ok {
+			continue
+		}
+
+		var inscope string
+		var names []string
+		for _, rtype := range []string{"common_name", "san_dns_name"} {
+			for _, n := range requests.OutgoingAssets(g, a, since, rtype) {
+				if fqdn, ok := n.Asset.(*domain.FQDN); ok {
+					names = append(names, fqdn.Name)
+
+					trimmed := strings.TrimPrefix(fqdn.Name, "*.")
+					if requests.DomainNameInScope(trimmed, domains) && (inscope == "" || trimmed < inscope) {
+						inscope = trimmed
+					}
+				}
+			}
+		}
+		if inscope == "" {
+			continue
+		}
+
+		for _, n := range names {
+			set.add(domains, n, &ExpansionEvidence{
+				Type:    EvidenceCertificate,
+				Value:   c.SerialNumber,
+				InScope: inscope,
+				Weight:  strongEvidence,
+			})
+		}
+	}
+}
+
+func sharedRegistrants(g *graph.Graph, domains []string, since time.Time, set expansionSet) {
+	records, err := g.DB.FindByType(oam.DomainRecord, since)
+	if err != nil {
+		return
+	}
+
+	for _, a := range records {
+		dr, ok := a.Asset.(*oamreg.DomainRecord)
+		if !ok || !requests.DomainNameInScope(dr.Domain, domains) {
+			continue
+		}
+
+		for _, cr := range requests.OutgoingAssets(g, a, since, "registrant_contact") {
+			for _, rtype := range []string{"organization", "email", "phone"} {
+				for _, detail := range requests.OutgoingAssets(g, cr, since, rtype) {
+					value, weight := registrantDetail(detail)
+					if value == "" {
+						continue
+					}
+
+					for _, other := range requests.IncomingAssets(g, detail, since, rtype) {
+						for _, reg := range requests.IncomingAssets(g, other, since, "registrant_contact") {
+							if odr, ok := reg.Asset.(*oamreg.DomainRecord); ok {
+								set.add(domains, odr.Domain, &ExpansionEvidence{
+									Type:    EvidenceRegistrant,
+									Value:   value,
+									InScope: strings.ToLower(dr.Domain),
+									Weight:  weight,
+								})
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// registrantDetail returns the value of the contact detail and its weight as evidence,
+// or an empty value when the detail was hidden by a privacy service.
+func registrantDetail(a *types.Asset) (string, int) {
+	var value string
+	weight := strongEvidence
+
+	switch v := a.Asset.(type) {
+	case *org.Organization:
+		value = v.Name
+	case *contact.EmailAddress:
+		value = strings.ToLower(v.Address)
+	case *contact.Phone:
+		value = v.E164
+		if value == "" {
+			value = v.Raw
+		}
+		weight = mediumEvidence
+	}
+
+	if PrivacyProtected(value) {
+		return "", 0
+	}
+	return value, weight
+}
+
+// PrivacyProtected returns true when the contact detail was hidden by a privacy service,
+// so that it is not evidence of ownership and must not join unrelated registrations.
+func PrivacyProtected(value string) bool {
+	value = strings.ToLower(value)
+
+	for _, s := range privacyProtectedValues {
+		if strings.Contains(value, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/graph"
+	oamcert "github.com/owasp-amass/open-asset-model/certificate"
+	"github.com/owasp-amass/open-asset-model/contact"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/org"
+	oamreg "github.com/owasp-amass/open-asset-model/registration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpansionCandidates(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+
+	// A name server operated by the target and a shared DNS provider
+	_, err := g.UpsertNS(ctx, "www.expandtest.domain", "ns1.expandtest.domain")
+	assert.Nil(t, err)
+	_, err = g.UpsertNS(ctx, "expandtest-corp.net", "ns1.expandtest.domain")
+	assert.Nil(t, err)
+	_, err = g.UpsertNS(ctx, "mail.expandtest.domain", "ns1.expandtest-provider.org")
+	assert.Nil(t, err)
+	_, err = g.UpsertNS(ctx, "unrelated-expandtest.org", "ns1.expandtest-provider.org")
+	assert.Nil(t, err)
+
+	// A shared public address, and a reserved address that is not evidence
+	_, err = g.UpsertA(ctx, "www.expandtest.domain", "93.184.216.77")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "shop.expandtest-corp.net", "93.184.216.77")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "mail.expandtest.domain", "10.20.30.40")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "intranet.expandtest-private.com", "10.20.30.40")
+	assert.Nil(t, err)
+
+	// A certificate covering names within and outside the scope
+	cert, err := g.DB.Create(nil, "", &oamcert.TLSCertificate{SerialNumber: "expandtest01", SubjectCommonName: "www.expandtest.domain"})
+	assert.Nil(t, err)
+	for rel, name := range map[string]string{"common_name": "www.expandtest.domain", "san_dns_name": "*.expandtest-brand.io"} {
+		_, err = g.DB.Create(cert, rel, &domain.FQDN{Name: name})
+		assert.Nil(t, err)
+	}
+
+	// Registrations sharing the registrant organization, and another hidden by a privacy service
+	for _, d := range []string{"expandtest.domain", "expandtest-brand.io", "expandtest-hidden.com"} {
+		name := "Expandtest Corp"
+		if d == "expandtest-hidden.com" {
+			name = "REDACTED FOR PRIVACY"
+		}
+
+		dr, err := g.DB.Create(nil, "", &oamreg.DomainRecord{Domain: d, Name: d})
+		assert.Nil(t, err)
+		cr, err := g.DB.Create(dr, "registrant_contact", &contact.ContactRecord{DiscoveredAt: "https://rdap.example/domain/" + d})
+		assert.Nil(t, err)
+		_, err = g.DB.Create(cr, "organization", &org.Organization{Name: name})
+		assert.Nil(t, err)
+	}
+
+	// Place the discovered names within the scope of the root domain
+	root, err := g.DB.Create(nil, "", &domain.FQDN{Name: "expandtest.domain"})
+	assert.Nil(t, err)
+	for _, sub := range []string{"www", "mail"} {
+		assets, err := g.DB.FindByContent(&domain.FQDN{Name: sub + ".expandtest.domain"}, time.Time{})
+		if assert.Nil(t, err) && assert.NotEmpty(t, assets) {
+			_, err = g.DB.Link(root, "node", assets[0])
+			assert.Nil(t, err)
+		}
+	}
+
+	candidates := ExpansionCandidates([]string{"expandtest.domain"}, time.Time{}, g)
+
+	if assert.Len(t, candidates, 3) {
+		c := candidates[0]
+		assert.Equal(t, "expandtest-brand.io", c.Domain)
+		assert.Equal(t, 6, c.Score)
+		if assert.Len(t, c.Evidence, 2) {
+			assert.Equal(t, EvidenceCertificate, c.Evidence[0].Type)
+			assert.Equal(t, "expandtest01", c.Evidence[0].Value)
+			assert.Equal(t, "www.expandtest.domain", c.Evidence[0].InScope)
+			assert.Equal(t, EvidenceRegistrant, c.Evidence[1].Type)
+			assert.Equal(t, "Expandtest Corp", c.Evidence[1].Value)
+		}
+
+		c = candidates[1]
+		assert.Equal(t, "expandtest-corp.net", c.Domain)
+		assert.Equal(t, 5, c.Score)
+		if assert.Len(t, c.Evidence, 2) {
+			assert.Equal(t, EvidenceNameServer, c.Evidence[0].Type)
+			assert.Equal(t, "ns1.expandtest.domain", c.Evidence[0].Value)
+			assert.Equal(t, EvidenceAddress, c.Evidence[1].Type)
+			assert.Equal(t, "shop.expandtest-corp.net", c.Evidence[1].Name)
+		}
+
+		c = candidates[2]
+		assert.Equal(t, "unrelated-expandtest.org", c.Domain)
+		assert.Equal(t, 1, c.Score)
+	}
+
+	assert.Empty(t, ExpansionCandidates([]string{}, time.Time{}, g))
+}
+
+func TestExpansionSharedProviders(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+
+	// A domain co-hosted on the same CDN addresses and DNS provider name servers as the scope
+	for i := 1; i <= 8; i++ {
+		addr := "93.184.216." + strconv.Itoa(100+i)
+
+		_, err := g.UpsertA(ctx, "www.cdntest.domain", addr)
+		assert.Nil(t, err)
+		_, err = g.UpsertA(ctx, "www.cdntest-app.com", addr)
+		assert.Nil(t, err)
+	}
+	for i := 1; i <= 4; i++ {
+		ns := "ns" + strconv.Itoa(i) + ".cdntest-dns.net"
+
+		_, err := g.UpsertNS(ctx, "www.cdntest.domain", ns)
+		assert.Nil(t, err)
+		_, err = g.UpsertNS(ctx, "www.cdntest-app.com", ns)
+		assert.Nil(t, err)
+	}
+
+	// A domain registered by the same organization
+	for _, d := range []string{"cdntest.domain", "cdntest-shop.org"} {
+		dr, err := g.DB.Create(nil, "", &oamreg.DomainRecord{Domain: d, Name: d})
+		assert.Nil(t, err)
+		cr, err := g.DB.Create(dr, "registrant_contact", &contact.ContactRecord{DiscoveredAt: "https://rdap.example/domain/" + d})
+		assert.Nil(t, err)
+		_, err = g.DB.Create(cr, "organization", &org.Organization{Name: "Cdntest Corp"})
+		assert.Nil(t, err)
+	}
+
+	root, err := g.DB.Create(nil, "", &domain.FQDN{Name: "cdntest.domain"})
+	assert.Nil(t, err)
+	assets, err := g.DB.FindByContent(&domain.FQDN{Name: "www.cdntest.domain"}, time.Time{})
+	if assert.Nil(t, err) && assert.NotEmpty(t, assets) {
+		_, err = g.DB.Link(root, "node", assets[0])
+		assert.Nil(t, err)
+	}
+
+	candidates := ExpansionCandidates([]string{"cdntest.domain"}, time.Time{}, g)
+
+	// The shared registrant ranks above the shared providers, despite the number of shared assets
+	if assert.Len(t, candidates, 2) {
+		assert.Equal(t, "cdntest-shop.org", candidates[0].Domain)
+		assert.Equal(t, strongEvidence, candidates[0].Score)
+
+		// Every shared address and name server is listed, but each type is scored once
+		assert.Equal(t, "cdntest-app.com", candidates[1].Domain)
+		assert.Equal(t, mediumEvidence+weakEvidence, candidates[1].Score)
+		assert.Len(t, candidates[1].Evidence, 12)
+	}
+}
+
+func TestPrivacyProtected(t *testing.T) {
+	for value, expected := range map[string]bool{
+		"REDACTED FOR PRIVACY":                 true,
+		"Data Protected, Not Disclosed":        true,
+		"Contact Privacy Inc. Customer 012345": true,
+		"Withheld for Privacy ehf":             true,
+		"Domains By Proxy, LLC":                true,
+		"Expandtest Corp":                      false,
+		"hostmaster@expandtest.domain":         false,
+		"":                                     false,
+	} {
+		assert.Equal(t, expected, PrivacyProtected(value), value)
+	}
+}
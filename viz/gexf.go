@@ -129,11 +129,17 @@ func WriteGEXFData(output io.Writer, nodes []Node, edges []Edge) error {
 				Attrs: []gexfAttribute{
 					{ID: "0", Title: "Title", Type: "string"},
 					{ID: "1", Title: "Type", Type: "string"},
+					{ID: "2", Title: "In-Degree", Type: "integer"},
+					{ID: "3", Title: "Out-Degree", Type: "integer"},
+					{ID: "4", Title: "Betweenness", Type: "double"},
+					{ID: "5", Title: "Component", Type: "integer"},
+					{ID: "6", Title: "Community", Type: "integer"},
 				},
 			},
 		},
 	}
 
+	metrics := AnalyzeGraph(nodes, edges)
 	for idx, n := range nodes {
 		var color *gexfColor
 
@@ -158,6 +164,11 @@ func WriteGEXFData(output io.Writer, nodes []Node, edges []Edge) error {
 			Attrs: []gexfAttrValue{
 				{For: "0", Value: n.Title},
 				{For: "1", Value: n.Type},
+				{For: "2", Value: strconv.Itoa(metrics[idx].InDegree)},
+				{For: "3", Value: strconv.Itoa(metrics[idx].OutDegree)},
+				{For: "4", Value: strconv.FormatFloat(metrics[idx].Betweenness, 'f', -1, 64)},
+				{For: "5", Value: strconv.Itoa(metrics[idx].Component)},
+				{For: "6", Value: strconv.Itoa(metrics[idx].Community)},
 			},
 			Color: color,
 		})
@@ -27,12 +27,22 @@ const expectedGexfOutput = `<creator>OWASP Amass - https://github.com/owasp-amas
           <attributes class="node">
               <attribute id="0" title="Title" type="string"></attribute>
               <attribute id="1" title="Type" type="string"></attribute>
+              <attribute id="2" title="In-Degree" type="integer"></attribute>
+              <attribute id="3" title="Out-Degree" type="integer"></attribute>
+              <attribute id="4" title="Betweenness" type="double"></attribute>
+              <attribute id="5" title="Component" type="integer"></attribute>
+              <attribute id="6" title="Community" type="integer"></attribute>
           </attributes>
           <nodes>
               <node id="0" label="owasp.org">
                   <attvalues>
                       <attvalue for="0" value="FQDN: owasp.org"></attvalue>
                       <attvalue for="1" value="FQDN"></attvalue>
+                      <attvalue for="2" value="0"></attvalue>
+                      <attvalue for="3" value="1"></attvalue>
+                      <attvalue for="4" value="0"></attvalue>
+                      <attvalue for="5" value="0"></attvalue>
+                      <attvalue for="6" value="0"></attvalue>
                   </attvalues>
                   <parents></parents>
                   <viz:color r="34" g="153" b="84"></viz:color>
@@ -41,6 +51,11 @@ const expectedGexfOutput = `<creator>OWASP Amass - https://github.com/owasp-amas
                   <attvalues>
                       <attvalue for="0" value="IPAddress: 205.251.199.98"></attvalue>
                       <attvalue for="1" value="IPAddress"></attvalue>
+                      <attvalue for="2" value="1"></attvalue>
+                      <attvalue for="3" value="0"></attvalue>
+                      <attvalue for="4" value="0"></attvalue>
+                      <attvalue for="5" value="0"></attvalue>
+                      <attvalue for="6" value="0"></attvalue>
                   </attvalues>
                   <parents></parents>
                   <viz:color r="243" g="156" b="18"></viz:color>
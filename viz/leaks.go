@@ -0,0 +1,164 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"sort"
+	"time"
+
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+)
+
+// Categories of the internal address space that should not be published in public DNS.
+const (
+	// InternalPrivate is the RFC 1918 private address space
+	InternalPrivate = "private"
+	// InternalLoopback is the IPv4 and IPv6 loopback address space
+	InternalLoopback = "loopback"
+	// InternalCGNAT is the RFC 6598 shared address space used by carrier-grade NAT
+	InternalCGNAT = "cgnat"
+	// InternalLinkLocal is the IPv4 and IPv6 link-local address space
+	InternalLinkLocal = "link-local"
+	// InternalULA is the IPv6 unique local address space
+	InternalULA = "ula"
+)
+
+// The reserved ranges returned by requests.IsReservedAddress that are internal address space
+var internalRanges = map[string]string{
+	"10.0.0.0/8":     InternalPrivate,
+	"172.16.0.0/12":  InternalPrivate,
+	"192.168.0.0/16": InternalPrivate,
+	"127.0.0.0/8":    InternalLoopback,
+	"::1/128":        InternalLoopback,
+	"100.64.0.0/10":  InternalCGNAT,
+	"169.254.0.0/16": InternalLinkLocal,
+	"fe80::/10":      InternalLinkLocal,
+	"fc00::/7":       InternalULA,
+}
+
+// InternalAddress is an internal address returned by an A or AAAA record.
+type InternalAddress struct {
+	Address string `json:"address"`
+	// Record is the type of the DNS record, A or AAAA
+	Record   string `json:"record"`
+	Range    string `json:"range"`
+	Category string `json:"category"`
+	// LastSeen is when the address record was last seen
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// AddressLeak is an in-scope name that resolves to internal address space.
+type AddressLeak struct {
+	Name     string    `json:"name"`
+	LastSeen time.Time `json:"last_seen"`
+	// Chain holds the CNAME targets followed before reaching the address records
+	Chain     []ChainLink        `json:"chain"`
+	Addresses []*InternalAddress `json:"addresses"`
+}
+
+// InternalAddressCategory returns the category of internal address space containing the address,
+// along with the matching range, or empty strings when the address is not internal.
+func InternalAddressCategory(addr string) (string, string) {
+	if reserved, cidr := requests.IsReservedAddress(addr); reserved {
+		if category, found := internalRanges[cidr]; found {
+			return category, cidr
+		}
+	}
+	return "", ""
+}
+
+// AddressLeaks returns the in-scope names that resolve to private, loopback, CGNAT, link-local or
+// unique local addresses, directly or through their CNAME chain.
+func AddressLeaks(domains []string, since time.Time, g *graph.Graph) []*AddressLeak {
+	var results []*AddressLeak
+	if len(domains) == 0 {
+		return results
+	}
+
+	var fqdns []oam.Asset
+	for _, d := range domains {
+		fqdns = append(fqdns, &domain.FQDN{Name: d})
+	}
+
+	if !since.IsZero() {
+		since = since.UTC()
+	}
+
+	assets, err := g.DB.FindByScope(fqdns, since)
+	if err != nil {
+		return results
+	}
+
+	seen := make(map[string]struct{})
+	for _, a := range assets {
+		n, ok := a.Asset.(*domain.FQDN)
+		if !ok || !requests.DomainNameInScope(n.Name, domains) {
+			continue
+		}
+		if _, found := seen[n.Name]; found {
+			continue
+		}
+		seen[n.Name] = struct{}{}
+
+		chain, final := followCNAMEChain(g, a, since)
+		rels, err := g.DB.OutgoingRelations(final, since, "a_record", "aaaa_record")
+		if err != nil {
+			continue
+		}
+
+		var addrs []*InternalAddress
+		for _, rel := range rels {
+			to, err := g.DB.FindById(rel.ToAsset.ID, since)
+			if err != nil || to == nil {
+				continue
+			}
+
+			ip, ok := to.Asset.(*network.IPAddress)
+			if !ok {
+				continue
+			}
+
+			if category, cidr := InternalAddressCategory(ip.Address.String()); category != "" {
+				record := "A"
+				if rel.Type == "aaaa_record" {
+					record = "AAAA"
+				}
+
+				addrs = append(addrs, &InternalAddress{
+					Address:  ip.Address.String(),
+					Record:   record,
+					Range:    cidr,
+					Category: category,
+					LastSeen: rel.LastSeen,
+				})
+			}
+		}
+		if len(addrs) == 0 {
+			continue
+		}
+
+		sort.Slice(addrs, func(i, j int) bool {
+			return addrs[i].Address < addrs[j].Address
+		})
+		if chain == nil {
+			chain = []ChainLink{}
+		}
+		results = append(results, &AddressLeak{
+			Name:      n.Name,
+			LastSeen:  a.LastSeen,
+			Chain:     chain,
+			Addresses: addrs,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
@@ -0,0 +1,120 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternalAddressCategory(t *testing.T) {
+	tests := []struct {
+		addr     string
+		category string
+		cidr     string
+	}{
+		{addr: "10.1.2.3", category: InternalPrivate, cidr: "10.0.0.0/8"},
+		{addr: "172.31.255.1", category: InternalPrivate, cidr: "172.16.0.0/12"},
+		{addr: "192.168.0.10", category: InternalPrivate, cidr: "192.168.0.0/16"},
+		{addr: "127.0.0.1", category: InternalLoopback, cidr: "127.0.0.0/8"},
+		{addr: "::1", category: InternalLoopback, cidr: "::1/128"},
+		{addr: "100.100.1.1", category: InternalCGNAT, cidr: "100.64.0.0/10"},
+		{addr: "169.254.169.254", category: InternalLinkLocal, cidr: "169.254.0.0/16"},
+		{addr: "fe80::1", category: InternalLinkLocal, cidr: "fe80::/10"},
+		{addr: "fd12:3456::1", category: InternalULA, cidr: "fc00::/7"},
+		// Reserved ranges that are not internal address space
+		{addr: "192.0.2.1"},
+		{addr: "224.0.0.1"},
+		{addr: "2001:db8::1"},
+		{addr: "93.184.216.34"},
+		{addr: "not an address"},
+	}
+
+	for _, test := range tests {
+		category, cidr := InternalAddressCategory(test.addr)
+		assert.Equal(t, test.category, category, test.addr)
+		assert.Equal(t, test.cidr, cidr, test.addr)
+	}
+}
+
+func TestAddressLeaks(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+
+	records := []struct {
+		name, addr string
+	}{
+		{name: "lb.leakstest.domain", addr: "10.20.30.42"},
+		{name: "vpn.leakstest.domain", addr: "100.64.1.2"},
+		{name: "vpn.leakstest.domain", addr: "93.184.216.90"},
+		{name: "local.leakstest.domain", addr: "127.0.0.1"},
+		{name: "www.leakstest.domain", addr: "93.184.216.90"},
+		{name: "docs.leakstest.domain", addr: "192.0.2.5"},
+	}
+	for _, rec := range records {
+		_, err := g.UpsertA(ctx, rec.name, rec.addr)
+		assert.Nil(t, err)
+	}
+	for _, addr := range []string{"fd00::10", "fe80::1"} {
+		_, err := g.UpsertAAAA(ctx, "v6.leakstest.domain", addr)
+		assert.Nil(t, err)
+	}
+	_, err := g.UpsertCNAME(ctx, "intranet.leakstest.domain", "lb.leakstest.domain")
+	assert.Nil(t, err)
+
+	root, err := g.DB.Create(nil, "", &domain.FQDN{Name: "leakstest.domain"})
+	assert.Nil(t, err)
+	for _, sub := range []string{"intranet", "vpn", "local", "www", "docs", "v6"} {
+		assets, err := g.DB.FindByContent(&domain.FQDN{Name: sub + ".leakstest.domain"}, time.Time{})
+		if assert.Nil(t, err) && assert.NotEmpty(t, assets) {
+			_, err = g.DB.Link(root, "node", assets[0])
+			assert.Nil(t, err)
+		}
+	}
+
+	leaks := AddressLeaks([]string{"leakstest.domain"}, time.Time{}, g)
+	if !assert.Len(t, leaks, 4) {
+		return
+	}
+
+	intranet := leaks[0]
+	assert.Equal(t, "intranet.leakstest.domain", intranet.Name)
+	if assert.Len(t, intranet.Chain, 1) && assert.Len(t, intranet.Addresses, 1) {
+		assert.Equal(t, "lb.leakstest.domain", intranet.Chain[0].Name)
+		assert.Equal(t, "10.20.30.42", intranet.Addresses[0].Address)
+		assert.Equal(t, "A", intranet.Addresses[0].Record)
+		assert.Equal(t, InternalPrivate, intranet.Addresses[0].Category)
+	}
+
+	local := leaks[1]
+	assert.Equal(t, "local.leakstest.domain", local.Name)
+	assert.Empty(t, local.Chain)
+	if assert.Len(t, local.Addresses, 1) {
+		assert.Equal(t, InternalLoopback, local.Addresses[0].Category)
+	}
+
+	v6 := leaks[2]
+	assert.Equal(t, "v6.leakstest.domain", v6.Name)
+	if assert.Len(t, v6.Addresses, 2) {
+		assert.Equal(t, "AAAA", v6.Addresses[0].Record)
+		assert.Equal(t, InternalULA, v6.Addresses[0].Category)
+		assert.Equal(t, InternalLinkLocal, v6.Addresses[1].Category)
+	}
+
+	// Only the internal addresses of a name are reported
+	vpn := leaks[3]
+	assert.Equal(t, "vpn.leakstest.domain", vpn.Name)
+	if assert.Len(t, vpn.Addresses, 1) {
+		assert.Equal(t, "100.64.1.2", vpn.Addresses[0].Address)
+		assert.Equal(t, InternalCGNAT, vpn.Addresses[0].Category)
+	}
+
+	assert.Empty(t, AddressLeaks(nil, time.Time{}, g))
+}
@@ -0,0 +1,343 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Limits placed on the Louvain community detection
+const (
+	maxLouvainLevels = 32
+	maxLouvainPasses = 100
+)
+
+// NodeMetrics contains the results of the graph analysis for a single viz package Node.
+type NodeMetrics struct {
+	InDegree    int
+	OutDegree   int
+	Betweenness float64
+	Component   int
+	Community   int
+}
+
+// AnalyzeGraph computes the degree, betweenness centrality, connected component and
+// community of each node. The returned slice is indexed the same as the nodes parameter.
+func AnalyzeGraph(nodes []Node, edges []Edge) []NodeMetrics {
+	metrics := make([]NodeMetrics, len(nodes))
+	if len(nodes) == 0 {
+		return metrics
+	}
+
+	for _, e := range edges {
+		if !validEdge(e, len(nodes)) {
+			continue
+		}
+		metrics[e.From].OutDegree++
+		metrics[e.To].InDegree++
+	}
+
+	adj := undirectedAdjacency(len(nodes), edges)
+	for i, b := range betweenness(adj) {
+		metrics[i].Betweenness = b
+	}
+	for i, c := range components(adj) {
+		metrics[i].Component = c
+	}
+	for i, c := range communities(adj) {
+		metrics[i].Community = c
+	}
+	return metrics
+}
+
+// WriteMetricsReport generates a text report of the nodes ranked by betweenness centrality and degree.
+// When limit is greater than zero, only that many of the highest ranked nodes are included.
+func WriteMetricsReport(output io.Writer, nodes []Node, edges []Edge, limit int) error {
+	metrics := AnalyzeGraph(nodes, edges)
+
+	comps := make(map[int]struct{})
+	comms := make(map[int]struct{})
+	ranked := make([]int, len(nodes))
+	for i := range nodes {
+		ranked[i] = i
+		comps[metrics[i].Component] = struct{}{}
+		comms[metrics[i].Community] = struct{}{}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := metrics[ranked[i]], metrics[ranked[j]]
+
+		if a.Betweenness != b.Betweenness {
+			return a.Betweenness > b.Betweenness
+		}
+		return a.InDegree+a.OutDegree > b.InDegree+b.OutDegree
+	})
+	if limit > 0 && limit < len(ranked) {
+		ranked = ranked[:limit]
+	}
+
+	if _, err := fmt.Fprintf(output, "OWASP Amass Network Mapping - Graph Metrics\n\n"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(output, "Nodes: %d\tEdges: %d\tComponents: %d\tCommunities: %d\n\n",
+		len(nodes), len(edges), len(comps), len(comms)); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(output, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tBETWEENNESS\tIN\tOUT\tCOMPONENT\tCOMMUNITY\tNODE")
+	for rank, idx := range ranked {
+		m := metrics[idx]
+
+		fmt.Fprintf(tw, "%d\t%.4f\t%d\t%d\t%d\t%d\t%s\n", rank+1, m.Betweenness,
+			m.InDegree, m.OutDegree, m.Component, m.Community, nodes[idx].Title)
+	}
+	return tw.Flush()
+}
+
+func validEdge(e Edge, num int) bool {
+	return e.From >= 0 && e.From < num && e.To >= 0 && e.To < num
+}
+
+func undirectedAdjacency(num int, edges []Edge) [][]int {
+	adj := make([][]int, num)
+	seen := make(map[[2]int]struct{}, len(edges))
+
+	for _, e := range edges {
+		if !validEdge(e, num) || e.From == e.To {
+			continue
+		}
+
+		key := [2]int{e.From, e.To}
+		if e.To < e.From {
+			key = [2]int{e.To, e.From}
+		}
+		if _, found := seen[key]; found {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		adj[e.From] = append(adj[e.From], e.To)
+		adj[e.To] = append(adj[e.To], e.From)
+	}
+	return adj
+}
+
+// betweenness implements the Brandes algorithm and returns values normalized to [0, 1].
+func betweenness(adj [][]int) []float64 {
+	num := len(adj)
+	cb := make([]float64, num)
+
+	sigma := make([]float64, num)
+	dist := make([]int, num)
+	delta := make([]float64, num)
+	preds := make([][]int, num)
+	for s := 0; s < num; s++ {
+		for i := 0; i < num; i++ {
+			sigma[i] = 0
+			dist[i] = -1
+			delta[i] = 0
+			preds[i] = preds[i][:0]
+		}
+		sigma[s] = 1
+		dist[s] = 0
+
+		var stack []int
+		queue := []int{s}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+
+			for _, w := range adj[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+
+			for _, v := range preds[w] {
+				delta[v] += (sigma[v] / sigma[w]) * (1 + delta[w])
+			}
+			if w != s {
+				cb[w] += delta[w]
+			}
+		}
+	}
+
+	if num > 2 {
+		// Each pair was counted from both ends of the undirected graph
+		scale := 1 / float64((num-1)*(num-2))
+		for i := range cb {
+			cb[i] *= scale
+		}
+	}
+	return cb
+}
+
+// components labels the connected components in order of their lowest node index.
+func components(adj [][]int) []int {
+	comp := make([]int, len(adj))
+	for i := range comp {
+		comp[i] = -1
+	}
+
+	var next int
+	for s := range adj {
+		if comp[s] >= 0 {
+			continue
+		}
+
+		comp[s] = next
+		queue := []int{s}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+
+			for _, w := range adj[v] {
+				if comp[w] < 0 {
+					comp[w] = next
+					queue = append(queue, w)
+				}
+			}
+		}
+		next++
+	}
+	return comp
+}
+
+// communities performs deterministic Louvain modularity optimization, breaking ties with the
+// lowest community index, and numbers the communities in order of their lowest node index.
+func communities(adj [][]int) []int {
+	num := len(adj)
+	// The weighted adjacency of the current level, where self-loops hold twice the internal weight
+	weights := make([]map[int]float64, num)
+	for v, neighbors := range adj {
+		weights[v] = make(map[int]float64, len(neighbors))
+		for _, w := range neighbors {
+			weights[v][w]++
+		}
+	}
+
+	membership := make([]int, num)
+	for i := range membership {
+		membership[i] = i
+	}
+
+	for level := 0; level < maxLouvainLevels; level++ {
+		comm, moved := louvainPhase(weights)
+		if !moved {
+			break
+		}
+
+		count := renumberInOrder(comm)
+		for i := range membership {
+			membership[i] = comm[membership[i]]
+		}
+
+		agg := make([]map[int]float64, count)
+		for i := range agg {
+			agg[i] = make(map[int]float64)
+		}
+		for v, neighbors := range weights {
+			for w, weight := range neighbors {
+				agg[comm[v]][comm[w]] += weight
+			}
+		}
+		weights = agg
+	}
+
+	renumberInOrder(membership)
+	return membership
+}
+
+func louvainPhase(weights []map[int]float64) ([]int, bool) {
+	num := len(weights)
+	comm := make([]int, num)
+	degree := make([]float64, num)
+	total := make([]float64, num)
+
+	var m2 float64
+	for v, neighbors := range weights {
+		comm[v] = v
+		for _, weight := range neighbors {
+			degree[v] += weight
+		}
+		total[v] = degree[v]
+		m2 += degree[v]
+	}
+	if m2 == 0 {
+		return comm, false
+	}
+
+	var moved bool
+	for pass := 0; pass < maxLouvainPasses; pass++ {
+		var changed bool
+
+		for v, neighbors := range weights {
+			links := make(map[int]float64)
+			for w, weight := range neighbors {
+				if w != v {
+					links[comm[w]] += weight
+				}
+			}
+
+			cur := comm[v]
+			total[cur] -= degree[v]
+
+			best := cur
+			bestGain := links[cur] - total[cur]*degree[v]/m2
+			candidates := make([]int, 0, len(links))
+			for c := range links {
+				candidates = append(candidates, c)
+			}
+			sort.Ints(candidates)
+
+			for _, c := range candidates {
+				if gain := links[c] - total[c]*degree[v]/m2; gain > bestGain+1e-12 {
+					best, bestGain = c, gain
+				}
+			}
+
+			total[best] += degree[v]
+			if best != cur {
+				comm[v] = best
+				changed = true
+				moved = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+	return comm, moved
+}
+
+// renumberInOrder replaces the labels with consecutive values in order of first appearance.
+func renumberInOrder(labels []int) int {
+	next := 0
+	renumber := make(map[int]int)
+
+	for i, label := range labels {
+		if _, found := renumber[label]; !found {
+			renumber[label] = next
+			next++
+		}
+		labels[i] = renumber[label]
+	}
+	return next
+}
@@ -0,0 +1,109 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeGraphDegreeAndBetweenness(t *testing.T) {
+	// A name server shared by three names is the chokepoint of the graph
+	nodes := metricsTestNodes(5)
+	edges := []Edge{
+		{From: 0, To: 3, Label: "ns_record"},
+		{From: 1, To: 3, Label: "ns_record"},
+		{From: 2, To: 3, Label: "ns_record"},
+		{From: 3, To: 4, Label: "a_record"},
+	}
+
+	metrics := AnalyzeGraph(nodes, edges)
+	assert.Len(t, metrics, 5)
+	assert.Equal(t, 3, metrics[3].InDegree)
+	assert.Equal(t, 1, metrics[3].OutDegree)
+	assert.Equal(t, 1, metrics[0].OutDegree)
+	assert.Equal(t, 0, metrics[0].InDegree)
+	assert.InDelta(t, 1.0, metrics[3].Betweenness, 1e-9)
+	for _, i := range []int{0, 1, 2, 4} {
+		assert.InDelta(t, 0.0, metrics[i].Betweenness, 1e-9)
+	}
+}
+
+func TestAnalyzeGraphComponents(t *testing.T) {
+	nodes := metricsTestNodes(5)
+	edges := []Edge{
+		{From: 0, To: 1, Label: "a_record"},
+		{From: 3, To: 2, Label: "a_record"},
+	}
+
+	metrics := AnalyzeGraph(nodes, edges)
+	assert.Equal(t, 0, metrics[0].Component)
+	assert.Equal(t, 0, metrics[1].Component)
+	assert.Equal(t, 1, metrics[2].Component)
+	assert.Equal(t, 1, metrics[3].Component)
+	assert.Equal(t, 2, metrics[4].Component)
+}
+
+func TestAnalyzeGraphCommunities(t *testing.T) {
+	// Two triangles joined by a single bridge form two communities
+	nodes := metricsTestNodes(6)
+	edges := []Edge{
+		{From: 0, To: 1}, {From: 1, To: 2}, {From: 2, To: 0},
+		{From: 3, To: 4}, {From: 4, To: 5}, {From: 5, To: 3},
+		{From: 2, To: 3},
+	}
+
+	metrics := AnalyzeGraph(nodes, edges)
+	for _, i := range []int{0, 1, 2} {
+		assert.Equal(t, 0, metrics[i].Community)
+	}
+	for _, i := range []int{3, 4, 5} {
+		assert.Equal(t, 1, metrics[i].Community)
+	}
+	assert.Equal(t, metrics[0].Component, metrics[5].Component)
+}
+
+func TestAnalyzeGraphEmpty(t *testing.T) {
+	assert.Empty(t, AnalyzeGraph(nil, nil))
+	assert.Empty(t, AnalyzeGraph([]Node{}, []Edge{{From: 0, To: 1}}))
+}
+
+func TestWriteMetricsReportRanking(t *testing.T) {
+	nodes := metricsTestNodes(5)
+	edges := []Edge{
+		{From: 0, To: 3}, {From: 1, To: 3}, {From: 2, To: 3}, {From: 3, To: 4},
+	}
+
+	buf := bytes.NewBufferString("")
+	assert.Nil(t, WriteMetricsReport(buf, nodes, edges, 2))
+
+	output := buf.String()
+	assert.Contains(t, output, "Nodes: 5\tEdges: 4\tComponents: 1\tCommunities: 1")
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	last := lines[len(lines)-2:]
+	assert.True(t, strings.HasPrefix(last[0], "1 "))
+	assert.True(t, strings.HasSuffix(last[0], "FQDN: node3.owasp.org"))
+	assert.True(t, strings.HasPrefix(last[1], "2 "))
+}
+
+func metricsTestNodes(num int) []Node {
+	var nodes []Node
+
+	for i := 0; i < num; i++ {
+		name := "node" + strconv.Itoa(i) + ".owasp.org"
+		nodes = append(nodes, Node{
+			ID:    i,
+			Type:  "FQDN",
+			Label: name,
+			Title: "FQDN: " + name,
+		})
+	}
+	return nodes
+}
@@ -0,0 +1,180 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"errors"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/domain"
+)
+
+// MaxScopePaths is the maximum number of shortest paths returned by ScopePaths.
+const MaxScopePaths = 10
+
+// PathHop represents a single relation on the path between a scope FQDN and another asset.
+type PathHop struct {
+	// From is the asset closer to the scope FQDN
+	From *types.Asset
+	// To is the asset closer to the asset being explained
+	To       *types.Asset
+	Relation string
+	// Forward is true when the relation is directed from the From asset to the To asset
+	Forward  bool
+	LastSeen time.Time
+}
+
+// ScopePath is the sequence of hops leading from a scope FQDN to the asset being explained.
+type ScopePath []PathHop
+
+type pathParent struct {
+	id      string
+	rel     *types.Relation
+	forward bool
+}
+
+// ScopePaths returns the shortest relation paths connecting the asset to a FQDN within the scope.
+// The search gives up when no path has been found within maxHops relations.
+func ScopePaths(asset oam.Asset, domains []string, maxHops int, since time.Time, g *graph.Graph) ([]ScopePath, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("no root domain names were provided")
+	}
+	if !since.IsZero() {
+		since = since.UTC()
+	}
+
+	starts, err := g.DB.FindByContent(asset, since)
+	if err != nil || len(starts) == 0 {
+		return nil, errors.New("the asset was not found in the graph")
+	}
+
+	assets := make(map[string]*types.Asset)
+	parents := make(map[string][]pathParent)
+	depth := make(map[string]int)
+
+	var frontier []string
+	for _, a := range starts {
+		if _, found := depth[a.ID]; !found {
+			depth[a.ID] = 0
+			assets[a.ID] = a
+			frontier = append(frontier, a.ID)
+		}
+	}
+
+	for d := 0; d <= maxHops && len(frontier) > 0; d++ {
+		var targets []string
+		for _, id := range frontier {
+			if n, ok := assets[id].Asset.(*domain.FQDN); ok && requests.DomainNameInScope(n.Name, domains) {
+				targets = append(targets, id)
+			}
+		}
+		if len(targets) > 0 {
+			var paths []ScopePath
+			for _, id := range targets {
+				paths = append(paths, buildScopePaths(id, assets, parents, MaxScopePaths-len(paths))...)
+				if len(paths) >= MaxScopePaths {
+					break
+				}
+			}
+			return paths, nil
+		}
+		if d == maxHops {
+			break
+		}
+
+		var next []string
+		for _, id := range frontier {
+			for _, step := range pathNeighbors(g, assets[id], since) {
+				nid := step.rel.ToAsset.ID
+				if !step.forward {
+					nid = step.rel.FromAsset.ID
+				}
+
+				if nd, found := depth[nid]; !found {
+					a, err := g.DB.FindById(nid, since)
+					if err != nil || a == nil || a.Asset == nil || a.Asset.AssetType() == oam.Source {
+						continue
+					}
+
+					depth[nid] = d + 1
+					assets[nid] = a
+					next = append(next, nid)
+				} else if nd != d+1 {
+					continue
+				}
+
+				parents[nid] = append(parents[nid], pathParent{
+					id:      id,
+					rel:     step.rel,
+					forward: step.forward,
+				})
+			}
+		}
+		frontier = next
+	}
+	return nil, errors.New("no path to the scope was found")
+}
+
+func pathNeighbors(g *graph.Graph, a *types.Asset, since time.Time) []pathParent {
+	var steps []pathParent
+
+	if rels, err := g.DB.OutgoingRelations(a, since); err == nil {
+		for _, rel := range rels {
+			if !ignoredPathRelation(rel.Type) {
+				steps = append(steps, pathParent{id: a.ID, rel: rel, forward: true})
+			}
+		}
+	}
+	if rels, err := g.DB.IncomingRelations(a, since); err == nil {
+		for _, rel := range rels {
+			if !ignoredPathRelation(rel.Type) {
+				steps = append(steps, pathParent{id: a.ID, rel: rel, forward: false})
+			}
+		}
+	}
+	return steps
+}
+
+// ignoredPathRelation reports whether the relation records provenance, so the path search and the ego view skip it.
+func ignoredPathRelation(rtype string) bool {
+	return rtype == "source" || rtype == "monitored_by"
+}
+
+// buildScopePaths walks the parents from the scope FQDN back to the starting asset.
+func buildScopePaths(id string, assets map[string]*types.Asset, parents map[string][]pathParent, limit int) []ScopePath {
+	if limit <= 0 {
+		return nil
+	}
+
+	plist := parents[id]
+	if len(plist) == 0 {
+		// The starting asset has been reached
+		return []ScopePath{{}}
+	}
+
+	var paths []ScopePath
+	for _, p := range plist {
+		for _, rest := range buildScopePaths(p.id, assets, parents, limit-len(paths)) {
+			hop := PathHop{
+				From:     assets[id],
+				To:       assets[p.id],
+				Relation: p.rel.Type,
+				// The search moved in the opposite direction of the path
+				Forward:  !p.forward,
+				LastSeen: p.rel.LastSeen,
+			}
+
+			paths = append(paths, append(ScopePath{hop}, rest...))
+			if len(paths) >= limit {
+				return paths
+			}
+		}
+	}
+	return paths
+}
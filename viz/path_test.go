@@ -0,0 +1,95 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopePaths(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+
+	ip, err := g.UpsertA(context.Background(), "www.pathtest.domain", "192.0.2.10")
+	assert.Nil(t, err)
+	nb, err := g.DB.Create(nil, "", &network.Netblock{CIDR: netip.MustParsePrefix("192.0.2.0/24"), Type: "IPv4"})
+	assert.Nil(t, err)
+	_, err = g.DB.Link(nb, "contains", ip)
+	assert.Nil(t, err)
+	_, err = g.DB.Create(nil, "", &network.AutonomousSystem{Number: 64500})
+	assert.Nil(t, err)
+	as, err := g.DB.FindByContent(&network.AutonomousSystem{Number: 64500}, time.Time{})
+	assert.Nil(t, err)
+	_, err = g.DB.Link(as[0], "announces", nb)
+	assert.Nil(t, err)
+
+	paths, err := ScopePaths(&network.AutonomousSystem{Number: 64500}, []string{"pathtest.domain"}, 5, time.Time{}, g)
+	assert.Nil(t, err)
+	if assert.Len(t, paths, 1) && assert.Len(t, paths[0], 3) {
+		p := paths[0]
+
+		assert.Equal(t, "www.pathtest.domain", p[0].From.Asset.Key())
+		assert.Equal(t, "a_record", p[0].Relation)
+		assert.True(t, p[0].Forward)
+		assert.Equal(t, "192.0.2.10", p[0].To.Asset.Key())
+
+		assert.Equal(t, "contains", p[1].Relation)
+		assert.False(t, p[1].Forward)
+		assert.Equal(t, "192.0.2.0/24", p[1].To.Asset.Key())
+
+		assert.Equal(t, "announces", p[2].Relation)
+		assert.False(t, p[2].Forward)
+		assert.Equal(t, "64500", p[2].To.Asset.Key())
+	}
+
+	_, err = ScopePaths(&network.AutonomousSystem{Number: 64500}, []string{"pathtest.domain"}, 2, time.Time{}, g)
+	assert.NotNil(t, err)
+
+	paths, err = ScopePaths(&domain.FQDN{Name: "www.pathtest.domain"}, []string{"pathtest.domain"}, 5, time.Time{}, g)
+	assert.Nil(t, err)
+	if assert.Len(t, paths, 1) {
+		assert.Empty(t, paths[0])
+	}
+}
+
+func TestParseAsset(t *testing.T) {
+	tt := []struct {
+		spec  string
+		atype string
+		key   string
+		err   bool
+	}{
+		{spec: "IPAddress:1.2.3.4", atype: "IPAddress", key: "1.2.3.4"},
+		{spec: "ipaddress:2001:db8::1", atype: "IPAddress", key: "2001:db8::1"},
+		{spec: "Netblock:192.0.2.7/24", atype: "Netblock", key: "192.0.2.0/24"},
+		{spec: "AutonomousSystem:AS13335", atype: "AutonomousSystem", key: "13335"},
+		{spec: "FQDN:WWW.Example.com", atype: "FQDN", key: "www.example.com"},
+		{spec: "EmailAddress:admin@example.com", atype: "EmailAddress", key: "admin@example.com"},
+		{spec: "Organization:Example, Inc.", atype: "Organization", key: "Example, Inc."},
+		{spec: "TLSCertificate:1234567890", atype: "TLSCertificate", key: "1234567890"},
+		{spec: "IPAddress:not-an-ip", err: true},
+		{spec: "Unknown:value", err: true},
+		{spec: "FQDN", err: true},
+	}
+
+	for _, tc := range tt {
+		a, err := ParseAsset(tc.spec)
+		if tc.err {
+			assert.NotNil(t, err, tc.spec)
+			continue
+		}
+		if assert.Nil(t, err, tc.spec) {
+			assert.Equal(t, tc.atype, string(a.AssetType()))
+			assert.Equal(t, tc.key, a.Key())
+		}
+	}
+}
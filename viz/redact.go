@@ -0,0 +1,111 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/owasp-amass/oam-tools/redact"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+// Prefixes added by newNode to the keys of some asset types
+const (
+	contactRecordPrefix = "Found->"
+	domainRecordPrefix  = "WHOIS: "
+	certificatePrefix   = "x509 Serial Number: "
+)
+
+// RedactNodes returns copies of the nodes with the labels and titles replaced by the pseudonyms
+// of the Redactor, so that the graph files can be shared without revealing the target.
+// Since the pseudonyms are deterministic, the edges between the nodes remain valid.
+func RedactNodes(nodes []Node, rd *redact.Redactor) []Node {
+	if rd == nil {
+		return nodes
+	}
+
+	results := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		label := redactLabel(oam.AssetType(n.Type), n.Label, rd)
+
+		results = append(results, Node{
+			ID:    n.ID,
+			Type:  n.Type,
+			Label: label,
+			Title: n.Type + ": " + label,
+		})
+	}
+	return results
+}
+
+func redactLabel(atype oam.AssetType, label string, rd *redact.Redactor) string {
+	switch atype {
+	case oam.FQDN:
+		return rd.Domain(label)
+	case oam.IPAddress:
+		return rd.IP(label)
+	case oam.Netblock:
+		return rd.Netblock(label)
+	case oam.AutonomousSystem:
+		if asn, err := strconv.Atoi(label); err == nil {
+			return strconv.Itoa(rd.ASN(asn))
+		}
+	case oam.SocketAddress:
+		if addr, err := netip.ParseAddrPort(label); err == nil {
+			return netip.AddrPortFrom(rd.Addr(addr.Addr()), addr.Port()).String()
+		}
+	case oam.NetworkEndpoint:
+		if host, port, err := net.SplitHostPort(label); err == nil {
+			return net.JoinHostPort(rd.Domain(host), port)
+		}
+	case oam.EmailAddress:
+		return rd.Email(label)
+	case oam.Organization:
+		return rd.Organization(label)
+	case oam.URL:
+		return redactURL(label, rd)
+	case oam.DomainRecord:
+		if d, found := strings.CutPrefix(label, domainRecordPrefix); found {
+			return domainRecordPrefix + rd.Domain(d)
+		}
+	case oam.TLSCertificate:
+		if serial, found := strings.CutPrefix(label, certificatePrefix); found {
+			return certificatePrefix + rd.Value(serial)
+		}
+	case oam.ContactRecord:
+		if source, found := strings.CutPrefix(label, contactRecordPrefix); found {
+			return contactRecordPrefix + redactURL(source, rd)
+		}
+	}
+	return rd.Value(label)
+}
+
+// redactURL keeps the scheme and port of the URL, while replacing the host, path and query.
+func redactURL(raw string, rd *redact.Redactor) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return rd.Value(raw)
+	}
+
+	host := u.Hostname()
+	if _, err := netip.ParseAddr(host); err == nil {
+		host = rd.IP(host)
+	} else {
+		host = rd.Domain(host)
+	}
+	if port := u.Port(); port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	redacted := &url.URL{Scheme: u.Scheme, Host: host}
+	if p := strings.Trim(u.EscapedPath()+u.RawQuery, "/"); p != "" {
+		redacted.Path = "/" + rd.Value(p)
+	}
+	return redacted.String()
+}
@@ -0,0 +1,55 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/owasp-amass/oam-tools/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactNodes(t *testing.T) {
+	rd := redact.NewRedactor([]byte("test key"))
+
+	nodes := []Node{
+		{ID: 0, Type: "FQDN", Label: "www.owasp.org", Title: "FQDN: www.owasp.org"},
+		{ID: 1, Type: "IPAddress", Label: "192.0.2.10", Title: "IPAddress: 192.0.2.10"},
+		{ID: 2, Type: "Netblock", Label: "192.0.2.0/24", Title: "Netblock: 192.0.2.0/24"},
+		{ID: 3, Type: "AutonomousSystem", Label: "64511", Title: "AutonomousSystem: 64511"},
+		{ID: 4, Type: "SocketAddress", Label: "192.0.2.10:443", Title: "SocketAddress: 192.0.2.10:443"},
+		{ID: 5, Type: "NetworkEndpoint", Label: "www.owasp.org:443", Title: "NetworkEndpoint: www.owasp.org:443"},
+		{ID: 6, Type: "DomainRecord", Label: "WHOIS: owasp.org", Title: "DomainRecord: WHOIS: owasp.org"},
+		{ID: 7, Type: "TLSCertificate", Label: "x509 Serial Number: 1234", Title: "TLSCertificate: x509 Serial Number: 1234"},
+		{ID: 8, Type: "URL", Label: "https://www.owasp.org:8443/login?next=1", Title: "URL: https://www.owasp.org:8443/login?next=1"},
+		{ID: 9, Type: "Organization", Label: "OWASP Foundation", Title: "Organization: OWASP Foundation"},
+	}
+
+	redacted := RedactNodes(nodes, rd)
+	assert.Len(t, redacted, len(nodes))
+	for i, n := range redacted {
+		assert.Equal(t, nodes[i].ID, n.ID)
+		assert.Equal(t, nodes[i].Type, n.Type)
+		assert.Equal(t, n.Type+": "+n.Label, n.Title)
+		assert.NotContains(t, strings.ToLower(n.Label), "owasp")
+	}
+
+	www := rd.Domain("www.owasp.org")
+	addr := netip.MustParseAddr(redacted[1].Label)
+	assert.Equal(t, www, redacted[0].Label)
+	assert.True(t, netip.MustParsePrefix(redacted[2].Label).Contains(addr))
+	assert.Equal(t, redacted[1].Label+":443", redacted[4].Label)
+	assert.Equal(t, www+":443", redacted[5].Label)
+	assert.Equal(t, "WHOIS: "+rd.Domain("owasp.org"), redacted[6].Label)
+	assert.True(t, strings.HasPrefix(redacted[7].Label, "x509 Serial Number: "))
+	assert.True(t, strings.HasPrefix(redacted[8].Label, "https://"+www+":8443/"))
+	assert.NotContains(t, redacted[8].Label, "login")
+
+	// The original nodes are left untouched, and no Redactor leaves the labels unchanged
+	assert.Equal(t, "www.owasp.org", nodes[0].Label)
+	assert.Equal(t, nodes, RedactNodes(nodes, nil))
+}
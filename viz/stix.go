@@ -0,0 +1,175 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"encoding/json"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	oam "github.com/owasp-amass/open-asset-model"
+)
+
+const (
+	stixSpecVersion string = "2.1"
+	stixTimeFormat  string = "2006-01-02T15:04:05.000Z"
+)
+
+// The namespace defined by the STIX 2.1 specification for deterministic SCO identifiers
+var stixSCONamespace = uuid.MustParse("00abedb4-aa42-466c-9c01-fed23315a9b7")
+
+type stixObject struct {
+	Type             string `json:"type"`
+	SpecVersion      string `json:"spec_version"`
+	ID               string `json:"id"`
+	Created          string `json:"created,omitempty"`
+	Modified         string `json:"modified,omitempty"`
+	Value            string `json:"value,omitempty"`
+	Number           int    `json:"number,omitempty"`
+	SerialNumber     string `json:"serial_number,omitempty"`
+	RelationshipType string `json:"relationship_type,omitempty"`
+	SourceRef        string `json:"source_ref,omitempty"`
+	TargetRef        string `json:"target_ref,omitempty"`
+}
+
+type stixBundle struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id"`
+	Objects []*stixObject `json:"objects"`
+}
+
+// WriteSTIXData generates a STIX 2.1 bundle of the infrastructure found in the Amass graph.
+func WriteSTIXData(output io.Writer, nodes []Node, edges []Edge) error {
+	bundle := &stixBundle{
+		Type:    "bundle",
+		ID:      "bundle--" + uuid.NewString(),
+		Objects: []*stixObject{},
+	}
+
+	ids := make(map[int]string, len(nodes))
+	for idx, node := range nodes {
+		if obj := newSTIXObservable(node); obj != nil {
+			ids[idx] = obj.ID
+			bundle.Objects = append(bundle.Objects, obj)
+		}
+	}
+
+	now := time.Now().UTC().Format(stixTimeFormat)
+	for _, edge := range edges {
+		from, fok := ids[edge.From]
+		to, tok := ids[edge.To]
+		if !fok || !tok {
+			continue
+		}
+
+		var rtype string
+		switch edge.Label {
+		case "a_record", "aaaa_record", "cname_record":
+			rtype = "resolves-to"
+		case "announces":
+			// STIX 2.1 only defines belongs-to from an address or prefix to the autonomous system
+			rtype = "belongs-to"
+			from, to = to, from
+		case "contains", "common_name", "san_dns_name", "san_ip_address":
+			rtype = "related-to"
+		default:
+			continue
+		}
+
+		bundle.Objects = append(bundle.Objects, &stixObject{
+			Type:             "relationship",
+			SpecVersion:      stixSpecVersion,
+			ID:               newSTIXRelationshipID(rtype, from, to),
+			Created:          now,
+			Modified:         now,
+			RelationshipType: rtype,
+			SourceRef:        from,
+			TargetRef:        to,
+		})
+	}
+
+	enc := json.NewEncoder(output)
+	enc.SetIndent("", "    ")
+	return enc.Encode(bundle)
+}
+
+func newSTIXObservable(node Node) *stixObject {
+	var stype string
+	var contrib any
+	obj := &stixObject{SpecVersion: stixSpecVersion}
+
+	switch node.Type {
+	case string(oam.FQDN):
+		stype = "domain-name"
+		obj.Value = node.Label
+		contrib = struct {
+			Value string `json:"value"`
+		}{Value: obj.Value}
+	case string(oam.IPAddress), string(oam.Netblock):
+		var is4 bool
+		if node.Type == string(oam.IPAddress) {
+			ip, err := netip.ParseAddr(node.Label)
+			if err != nil {
+				return nil
+			}
+			is4 = ip.Unmap().Is4()
+		} else {
+			prefix, err := netip.ParsePrefix(node.Label)
+			if err != nil {
+				return nil
+			}
+			is4 = prefix.Addr().Is4()
+		}
+
+		stype = "ipv6-addr"
+		if is4 {
+			stype = "ipv4-addr"
+		}
+		obj.Value = node.Label
+		contrib = struct {
+			Value string `json:"value"`
+		}{Value: obj.Value}
+	case string(oam.AutonomousSystem):
+		num, err := strconv.Atoi(node.Label)
+		if err != nil {
+			return nil
+		}
+
+		stype = "autonomous-system"
+		obj.Number = num
+		contrib = struct {
+			Number int `json:"number"`
+		}{Number: num}
+	case string(oam.TLSCertificate):
+		stype = "x509-certificate"
+		obj.SerialNumber = strings.TrimPrefix(node.Label, "x509 Serial Number: ")
+		contrib = struct {
+			SerialNumber string `json:"serial_number"`
+		}{SerialNumber: obj.SerialNumber}
+	default:
+		return nil
+	}
+
+	// SCO identifiers are UUIDv5 values generated from the ID contributing properties
+	name, err := json.Marshal(contrib)
+	if err != nil {
+		return nil
+	}
+
+	obj.Type = stype
+	obj.ID = stype + "--" + uuid.NewSHA1(stixSCONamespace, name).String()
+	return obj
+}
+
+// newSTIXRelationshipID returns a UUIDv5 identifier, so the same relationship keeps its identifier across exports.
+func newSTIXRelationshipID(rtype, from, to string) string {
+	name := strings.Join([]string{rtype, from, to}, "|")
+
+	return "relationship--" + uuid.NewSHA1(stixSCONamespace, []byte(name)).String()
+}
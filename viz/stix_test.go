@@ -0,0 +1,206 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Patterns taken from the STIX 2.1 JSON schemas (common/core, common/timestamp)
+var (
+	stixTypeRE      = regexp.MustCompile(`^\-?[a-z0-9]+(-[a-z0-9]+)*\-?$`)
+	stixTimestampRE = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?Z$`)
+	stixRelTypeRE   = regexp.MustCompile(`^[a-z0-9\-]+$`)
+)
+
+// The embedded relationships defined by the STIX 2.1 cyber-observable objects in the bundle
+var stixRelationshipPairs = []string{
+	"domain-name resolves-to ipv4-addr",
+	"domain-name resolves-to ipv6-addr",
+	"domain-name resolves-to domain-name",
+	"ipv4-addr belongs-to autonomous-system",
+	"ipv6-addr belongs-to autonomous-system",
+}
+
+func TestWriteSTIXDataHappyPath(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	err := WriteSTIXData(buf, stixTestNodes(), stixTestEdges())
+	assert.Nil(t, err)
+
+	bundle := validateSTIXBundle(t, buf.Bytes())
+
+	objs := make(map[string]*stixObject)
+	for _, obj := range bundle.Objects {
+		objs[obj.ID] = obj
+	}
+	// The service node has no matching SCO and must be left out
+	assert.Len(t, objs, 9)
+
+	domainID := "domain-name--" + uuid.NewSHA1(stixSCONamespace, []byte(`{"value":"owasp.org"}`)).String()
+	addrID := "ipv4-addr--" + uuid.NewSHA1(stixSCONamespace, []byte(`{"value":"205.251.199.98"}`)).String()
+	netID := "ipv4-addr--" + uuid.NewSHA1(stixSCONamespace, []byte(`{"value":"205.251.192.0/21"}`)).String()
+	asID := "autonomous-system--" + uuid.NewSHA1(stixSCONamespace, []byte(`{"number":16509}`)).String()
+	for _, id := range []string{domainID, addrID, netID, asID} {
+		assert.Contains(t, objs, id)
+	}
+	if assert.Contains(t, objs, asID) {
+		assert.Equal(t, 16509, objs[asID].Number)
+	}
+
+	rels := make(map[string]string)
+	for _, obj := range objs {
+		if obj.Type == "relationship" {
+			rels[obj.SourceRef+"|"+obj.TargetRef] = obj.RelationshipType
+		}
+	}
+	assert.Equal(t, "resolves-to", rels[domainID+"|"+addrID])
+	assert.Equal(t, "related-to", rels[netID+"|"+addrID])
+	assert.NotContains(t, rels, addrID+"|"+netID)
+	assert.Equal(t, "belongs-to", rels[netID+"|"+asID])
+}
+
+func TestWriteSTIXDataDeterministicIDs(t *testing.T) {
+	first := bytes.NewBufferString("")
+	second := bytes.NewBufferString("")
+	assert.Nil(t, WriteSTIXData(first, testNodes(), nil))
+	assert.Nil(t, WriteSTIXData(second, testNodes(), nil))
+
+	var b1, b2 stixBundle
+	assert.Nil(t, json.Unmarshal(first.Bytes(), &b1))
+	assert.Nil(t, json.Unmarshal(second.Bytes(), &b2))
+	assert.NotEqual(t, b1.ID, b2.ID)
+	assert.Equal(t, b1.Objects, b2.Objects)
+
+	// Relationships keep their identifiers across exports as well
+	var ids [2][]string
+	for i := range ids {
+		buf := bytes.NewBufferString("")
+		assert.Nil(t, WriteSTIXData(buf, stixTestNodes(), stixTestEdges()))
+
+		for _, obj := range validateSTIXBundle(t, buf.Bytes()).Objects {
+			if obj.Type == "relationship" {
+				ids[i] = append(ids[i], obj.ID)
+			}
+		}
+	}
+	assert.NotEmpty(t, ids[0])
+	assert.Equal(t, ids[0], ids[1])
+}
+
+// validateSTIXBundle decodes the bundle without accepting unknown properties and checks the
+// identifiers, common properties and references of every object against the STIX 2.1 specification.
+func validateSTIXBundle(t *testing.T, data []byte) *stixBundle {
+	var bundle stixBundle
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// A STIX 2.1 bundle has no spec_version, and the objects only carry the supported properties
+	dec.DisallowUnknownFields()
+	if !assert.Nil(t, dec.Decode(&bundle)) {
+		return &bundle
+	}
+
+	assert.Equal(t, "bundle", bundle.Type)
+	if u, err := uuid.Parse(strings.TrimPrefix(bundle.ID, "bundle--")); assert.True(t,
+		strings.HasPrefix(bundle.ID, "bundle--"), "bundle id %s", bundle.ID) && assert.Nil(t, err) {
+		assert.Equal(t, uuid.RFC4122, u.Variant())
+	}
+	if !assert.NotNil(t, bundle.Objects, "objects must be an array") {
+		return &bundle
+	}
+
+	ids := make(map[string]string, len(bundle.Objects))
+	for _, obj := range bundle.Objects {
+		_, dup := ids[obj.ID]
+		assert.False(t, dup, "duplicate id %s", obj.ID)
+		ids[obj.ID] = obj.Type
+	}
+
+	for _, obj := range bundle.Objects {
+		assert.Regexp(t, stixTypeRE, obj.Type)
+		assert.True(t, len(obj.Type) >= 3 && len(obj.Type) <= 250)
+		assert.Equal(t, "2.1", obj.SpecVersion, obj.ID)
+
+		// Every identifier is the object type followed by a UUIDv5
+		prefix, id, found := strings.Cut(obj.ID, "--")
+		assert.True(t, found, "id %s has no separator", obj.ID)
+		assert.Equal(t, obj.Type, prefix, "id %s must begin with the object type", obj.ID)
+		if u, err := uuid.Parse(id); assert.Nil(t, err, obj.ID) {
+			assert.Equal(t, uuid.Version(5), u.Version(), obj.ID)
+			assert.Equal(t, uuid.RFC4122, u.Variant(), obj.ID)
+			assert.Equal(t, strings.ToLower(id), id, obj.ID)
+		}
+
+		var contrib string
+		switch obj.Type {
+		case "relationship":
+			for _, ts := range []string{obj.Created, obj.Modified} {
+				assert.Regexp(t, stixTimestampRE, ts)
+			}
+			assert.Regexp(t, stixRelTypeRE, obj.RelationshipType)
+			for _, ref := range []string{obj.SourceRef, obj.TargetRef} {
+				_, found := ids[ref]
+				assert.True(t, found, "%s references %s outside the bundle", obj.ID, ref)
+			}
+
+			// The source and target types must be allowed by the relationship type
+			pair := ids[obj.SourceRef] + " " + obj.RelationshipType + " " + ids[obj.TargetRef]
+			switch obj.RelationshipType {
+			case "resolves-to", "belongs-to":
+				assert.Contains(t, stixRelationshipPairs, pair)
+			case "related-to":
+				assert.NotEqual(t, "relationship", ids[obj.SourceRef], pair)
+				assert.NotEqual(t, "relationship", ids[obj.TargetRef], pair)
+			default:
+				t.Errorf("unexpected STIX relationship type: %s", obj.RelationshipType)
+			}
+			assert.Equal(t, newSTIXRelationshipID(obj.RelationshipType, obj.SourceRef, obj.TargetRef), obj.ID)
+			continue
+		case "domain-name", "ipv4-addr", "ipv6-addr":
+			assert.NotEmpty(t, obj.Value, obj.ID)
+			contrib = fmt.Sprintf(`{"value":%q}`, obj.Value)
+		case "autonomous-system":
+			assert.Positive(t, obj.Number, obj.ID)
+			contrib = fmt.Sprintf(`{"number":%d}`, obj.Number)
+		case "x509-certificate":
+			assert.NotEmpty(t, obj.SerialNumber, obj.ID)
+			contrib = fmt.Sprintf(`{"serial_number":%q}`, obj.SerialNumber)
+		default:
+			t.Errorf("unexpected STIX object type: %s", obj.Type)
+			continue
+		}
+
+		// SCOs are free of the SDO and SRO common properties
+		assert.Empty(t, obj.Created+obj.Modified+obj.RelationshipType+obj.SourceRef+obj.TargetRef, obj.ID)
+		// The UUIDv5 is generated in the STIX namespace from the ID contributing properties
+		assert.Equal(t, obj.Type+"--"+uuid.NewSHA1(stixSCONamespace, []byte(contrib)).String(), obj.ID)
+	}
+	return &bundle
+}
+
+func stixTestEdges() []Edge {
+	return append(testEdges(), []Edge{
+		{From: 2, To: 1, Label: "contains", Title: "contains"},
+		{From: 3, To: 2, Label: "announces", Title: "announces"},
+		{From: 4, To: 0, Label: "san_dns_name", Title: "san_dns_name"},
+		{From: 1, To: 5, Label: "port", Title: "port"},
+	}...)
+}
+
+func stixTestNodes() []Node {
+	return append(testNodes(), []Node{
+		{ID: 2, Type: "Netblock", Label: "205.251.192.0/21", Title: "Netblock: 205.251.192.0/21"},
+		{ID: 3, Type: "AutonomousSystem", Label: "16509", Title: "AutonomousSystem: 16509"},
+		{ID: 4, Type: "TLSCertificate", Label: "x509 Serial Number: 1234", Title: "TLSCertificate: x509 Serial Number: 1234"},
+		{ID: 5, Type: "Service", Label: "abcdef", Title: "Service: abcdef"},
+	}...)
+}
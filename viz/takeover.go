@@ -0,0 +1,207 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/asset-db/types"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
+	oam "github.com/owasp-amass/open-asset-model"
+	"github.com/owasp-amass/open-asset-model/domain"
+)
+
+// The maximum number of CNAME records followed from an in-scope name
+const maxTakeoverChain = 10
+
+// TakeoverSuffixes is the built-in list of service domains where a CNAME target can often be
+// claimed by a third party once the resource it pointed to has been deleted.
+var TakeoverSuffixes = []string{
+	"agilecrm.com",
+	"azure-api.net",
+	"azureedge.net",
+	"azurewebsites.net",
+	"bitbucket.io",
+	"blob.core.windows.net",
+	"cargocollective.com",
+	"cloudapp.azure.com",
+	"cloudapp.net",
+	"cloudfront.net",
+	"elasticbeanstalk.com",
+	"feedpress.me",
+	"firebaseapp.com",
+	"fly.dev",
+	"freshdesk.com",
+	"ghost.io",
+	"github.io",
+	"gitlab.io",
+	"helpjuice.com",
+	"helpscoutdocs.com",
+	"herokuapp.com",
+	"herokudns.com",
+	"launchrock.com",
+	"myshopify.com",
+	"netlify.app",
+	"pantheonsite.io",
+	"readme.io",
+	"readthedocs.io",
+	"s3.amazonaws.com",
+	"statuspage.io",
+	"strikingly.com",
+	"surge.sh",
+	"trafficmanager.net",
+	"unbouncepages.com",
+	"uservoice.com",
+	"vercel.app",
+	"webflow.io",
+	"wordpress.com",
+	"wpengine.com",
+	"zendesk.com",
+}
+
+// ChainLink is a single CNAME target on the chain followed from an in-scope name.
+type ChainLink struct {
+	Name string `json:"name"`
+	// LastSeen is when the CNAME record pointing to this name was last seen
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// TakeoverCandidate is an in-scope name whose CNAME chain could allow a subdomain takeover.
+type TakeoverCandidate struct {
+	Name     string      `json:"name"`
+	LastSeen time.Time   `json:"last_seen"`
+	Chain    []ChainLink `json:"chain"`
+	// Dangling is true when the final target is outside the scope and has no address records
+	Dangling bool `json:"dangling"`
+	// Service is the takeover-prone service suffix matched by a target on the chain
+	Service string `json:"service,omitempty"`
+}
+
+// Target returns the final name on the CNAME chain.
+func (c *TakeoverCandidate) Target() string {
+	if len(c.Chain) == 0 {
+		return ""
+	}
+	return c.Chain[len(c.Chain)-1].Name
+}
+
+// TakeoverCandidates flags the in-scope names whose CNAME chain ends outside the scope at a name
+// without address records, or passes through a name matching one of the service suffixes.
+func TakeoverCandidates(domains, suffixes []string, since time.Time, g *graph.Graph) []*TakeoverCandidate {
+	var results []*TakeoverCandidate
+	if len(domains) == 0 {
+		return results
+	}
+
+	var fqdns []oam.Asset
+	for _, d := range domains {
+		fqdns = append(fqdns, &domain.FQDN{Name: d})
+	}
+
+	if !since.IsZero() {
+		since = since.UTC()
+	}
+
+	assets, err := g.DB.FindByScope(fqdns, since)
+	if err != nil {
+		return results
+	}
+
+	seen := make(map[string]struct{})
+	for _, a := range assets {
+		n, ok := a.Asset.(*domain.FQDN)
+		if !ok || !requests.DomainNameInScope(n.Name, domains) {
+			continue
+		}
+		if _, found := seen[n.Name]; found {
+			continue
+		}
+		seen[n.Name] = struct{}{}
+
+		chain, final := followCNAMEChain(g, a, since)
+		if len(chain) == 0 {
+			continue
+		}
+
+		c := &TakeoverCandidate{
+			Name:     n.Name,
+			LastSeen: a.LastSeen,
+			Chain:    chain,
+		}
+		for _, link := range chain {
+			if s := matchTakeoverSuffix(link.Name, suffixes); s != "" {
+				c.Service = s
+				break
+			}
+		}
+
+		target := c.Target()
+		if !requests.DomainNameInScope(target, domains) && !hasAddressRecords(g, final, since) {
+			c.Dangling = true
+		}
+		if c.Dangling || c.Service != "" {
+			results = append(results, c)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+func followCNAMEChain(g *graph.Graph, a *types.Asset, since time.Time) ([]ChainLink, *types.Asset) {
+	var chain []ChainLink
+	visited := map[string]struct{}{a.ID: {}}
+
+	cur := a
+	for i := 0; i < maxTakeoverChain; i++ {
+		rels, err := g.DB.OutgoingRelations(cur, since, "cname_record")
+		if err != nil || len(rels) == 0 {
+			break
+		}
+
+		rel := rels[0]
+		if _, found := visited[rel.ToAsset.ID]; found {
+			break
+		}
+
+		next, err := g.DB.FindById(rel.ToAsset.ID, since)
+		if err != nil || next == nil {
+			break
+		}
+
+		n, ok := next.Asset.(*domain.FQDN)
+		if !ok {
+			break
+		}
+
+		visited[next.ID] = struct{}{}
+		chain = append(chain, ChainLink{Name: n.Name, LastSeen: rel.LastSeen})
+		cur = next
+	}
+	return chain, cur
+}
+
+func hasAddressRecords(g *graph.Graph, a *types.Asset, since time.Time) bool {
+	rels, err := g.DB.OutgoingRelations(a, since, "a_record", "aaaa_record")
+	return err == nil && len(rels) > 0
+}
+
+func matchTakeoverSuffix(name string, suffixes []string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+	for _, s := range suffixes {
+		s = strings.ToLower(strings.Trim(s, "."))
+
+		if s != "" && (name == s || strings.HasSuffix(name, "."+s)) {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+// SPDX-License-Identifier: Apache-2.0
+
+package viz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/open-asset-model/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTakeoverCandidates(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+	ctx := context.Background()
+
+	// Dangling: the target is outside the scope and has no address records
+	_, err := g.UpsertCNAME(ctx, "old.takeover.domain", "gone.vendor.net")
+	assert.Nil(t, err)
+	// Takeover-prone service that still resolves
+	_, err = g.UpsertCNAME(ctx, "docs.takeover.domain", "takeover-docs.github.io")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "takeover-docs.github.io", "185.199.108.153")
+	assert.Nil(t, err)
+	// Chains through an in-scope alias to an unresolved bucket
+	_, err = g.UpsertCNAME(ctx, "cdn.takeover.domain", "assets.takeover.domain")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(ctx, "assets.takeover.domain", "takeover-assets.s3.amazonaws.com")
+	assert.Nil(t, err)
+	// Healthy alias to a resolving vendor
+	_, err = g.UpsertCNAME(ctx, "www.takeover.domain", "edge.vendor.net")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(ctx, "edge.vendor.net", "192.0.2.80")
+	assert.Nil(t, err)
+	// In-scope target without address records is not dangling
+	_, err = g.UpsertCNAME(ctx, "alias.takeover.domain", "internal.takeover.domain")
+	assert.Nil(t, err)
+
+	// Place the discovered names within the scope of the root domain
+	root, err := g.DB.Create(nil, "", &domain.FQDN{Name: "takeover.domain"})
+	assert.Nil(t, err)
+	for _, sub := range []string{"old", "docs", "cdn", "assets", "www", "alias", "internal"} {
+		assets, err := g.DB.FindByContent(&domain.FQDN{Name: sub + ".takeover.domain"}, time.Time{})
+		if assert.Nil(t, err) && assert.NotEmpty(t, assets) {
+			_, err = g.DB.Link(root, "node", assets[0])
+			assert.Nil(t, err)
+		}
+	}
+
+	results := TakeoverCandidates([]string{"takeover.domain"}, TakeoverSuffixes, time.Time{}, g)
+
+	byName := make(map[string]*TakeoverCandidate)
+	for _, c := range results {
+		byName[c.Name] = c
+	}
+	assert.Len(t, results, 4)
+
+	if c, found := byName["old.takeover.domain"]; assert.True(t, found) {
+		assert.True(t, c.Dangling)
+		assert.Empty(t, c.Service)
+		assert.Equal(t, "gone.vendor.net", c.Target())
+		assert.False(t, c.Chain[0].LastSeen.IsZero())
+	}
+	if c, found := byName["docs.takeover.domain"]; assert.True(t, found) {
+		assert.False(t, c.Dangling)
+		assert.Equal(t, "github.io", c.Service)
+	}
+	if c, found := byName["cdn.takeover.domain"]; assert.True(t, found) {
+		assert.True(t, c.Dangling)
+		assert.Equal(t, "s3.amazonaws.com", c.Service)
+		if assert.Len(t, c.Chain, 2) {
+			assert.Equal(t, "assets.takeover.domain", c.Chain[0].Name)
+			assert.Equal(t, "takeover-assets.s3.amazonaws.com", c.Chain[1].Name)
+		}
+	}
+	assert.Contains(t, byName, "assets.takeover.domain")
+	assert.NotContains(t, byName, "www.takeover.domain")
+	assert.NotContains(t, byName, "alias.takeover.domain")
+
+	// The suffix list can be extended by the caller
+	results = TakeoverCandidates([]string{"takeover.domain"}, append(TakeoverSuffixes, "vendor.net"), time.Time{}, g)
+	assert.Len(t, results, 5)
+}
+
+func TestMatchTakeoverSuffix(t *testing.T) {
+	suffixes := []string{"github.io", ".herokuapp.com."}
+
+	assert.Equal(t, "github.io", matchTakeoverSuffix("Owner.GitHub.io.", suffixes))
+	assert.Equal(t, "herokuapp.com", matchTakeoverSuffix("app.herokuapp.com", suffixes))
+	assert.Empty(t, matchTakeoverSuffix("notgithub.io", suffixes))
+	assert.Empty(t, matchTakeoverSuffix("example.com", suffixes))
+}
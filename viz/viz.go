@@ -11,6 +11,7 @@ import (
 	assetdb "github.com/owasp-amass/asset-db"
 	"github.com/owasp-amass/asset-db/types"
 	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/oam-tools/requests"
 	oam "github.com/owasp-amass/open-asset-model"
 	oamcert "github.com/owasp-amass/open-asset-model/certificate"
 	"github.com/owasp-amass/open-asset-model/contact"
@@ -87,7 +88,7 @@ func VizData(domains []string, since time.Time, g *graph.Graph) ([]Node, []Edge)
 			var inRels, outRels []string
 			switch a.Asset.AssetType() {
 			case oam.FQDN:
-				if domainNameInScope(n.Label, domains) {
+				if requests.DomainNameInScope(n.Label, domains) {
 					in = true
 					out = true
 				} else if associatedWithScope(g.DB, a, domains, since) {
@@ -206,6 +207,122 @@ func VizData(domains []string, since time.Time, g *graph.Graph) ([]Node, []Edge)
 	return nodes, edges
 }
 
+// EgoData returns the viz package Nodes and Edges found within radius relations of the provided asset.
+func EgoData(asset oam.Asset, radius int, since time.Time, g *graph.Graph) ([]Node, []Edge) {
+	if asset == nil {
+		return []Node{}, []Edge{}
+	}
+
+	if !since.IsZero() {
+		since = since.UTC()
+	}
+
+	next, err := g.DB.FindByContent(asset, since)
+	if err != nil || len(next) == 0 {
+		return []Node{}, []Edge{}
+	}
+
+	var idx int
+	var nodes []Node
+	var edges []Edge
+	nodeToIdx := make(map[string]int)
+	// Returns the index assigned to the asset and whether the node was just added
+	addNode := func(a *types.Asset) (int, bool) {
+		n := newNode(g.DB, idx, a, since)
+		if n == nil {
+			return -1, false
+		}
+		if id, found := nodeToIdx[n.Label]; found {
+			return id, false
+		}
+
+		nodeToIdx[n.Label] = idx
+		nodes = append(nodes, *n)
+		idx++
+		return n.ID, true
+	}
+
+	var start []*types.Asset
+	for _, a := range next {
+		if _, added := addNode(a); added {
+			start = append(start, a)
+		}
+	}
+	next = start
+
+	expanded := make(map[string]struct{})
+	relSeen := make(map[string]struct{})
+	for hop := 0; hop < radius && len(next) > 0; hop++ {
+		var assets []*types.Asset
+		assets = append(assets, next...)
+		next = []*types.Asset{}
+
+		for _, a := range assets {
+			if _, found := expanded[a.ID]; found {
+				continue
+			}
+			expanded[a.ID] = struct{}{}
+
+			id, _ := addNode(a)
+			if id < 0 {
+				continue
+			}
+
+			if rels, err := g.DB.OutgoingRelations(a, since); err == nil {
+				for _, rel := range rels {
+					if _, found := relSeen[rel.ID]; found || ignoredPathRelation(rel.Type) {
+						continue
+					}
+					relSeen[rel.ID] = struct{}{}
+
+					if to, err := g.DB.FindById(rel.ToAsset.ID, since); err == nil {
+						toID, added := addNode(to)
+						if toID < 0 {
+							continue
+						}
+						if added {
+							next = append(next, to)
+						}
+
+						edges = append(edges, Edge{
+							From:  id,
+							To:    toID,
+							Label: rel.Type,
+							Title: rel.Type,
+						})
+					}
+				}
+			}
+			if rels, err := g.DB.IncomingRelations(a, since); err == nil {
+				for _, rel := range rels {
+					if _, found := relSeen[rel.ID]; found || ignoredPathRelation(rel.Type) {
+						continue
+					}
+					relSeen[rel.ID] = struct{}{}
+
+					if from, err := g.DB.FindById(rel.FromAsset.ID, since); err == nil {
+						fromID, added := addNode(from)
+						if fromID < 0 {
+							continue
+						}
+						if added {
+							next = append(next, from)
+						}
+
+						edges = append(edges, Edge{
+							From:  fromID,
+							To:    id,
+							Label: rel.Type,
+							Title: rel.Type,
+						})
+					}
+				}
+			}
+		}
+	}
+	return nodes, edges
+}
+
 func newNode(db *assetdb.AssetDB, idx int, a *types.Asset, since time.Time) *Node {
 	if a == nil || a.Asset == nil {
 		return nil
@@ -255,27 +372,11 @@ func newNode(db *assetdb.AssetDB, idx int, a *types.Asset, since time.Time) *Nod
 	}
 }
 
-func domainNameInScope(name string, scope []string) bool {
-	var discovered bool
-
-	n := strings.ToLower(strings.TrimSpace(name))
-	for _, d := range scope {
-		d = strings.ToLower(d)
-
-		if n == d || strings.HasSuffix(n, "."+d) {
-			discovered = true
-			break
-		}
-	}
-
-	return discovered
-}
-
 func associatedWithScope(db *assetdb.AssetDB, asset *types.Asset, scope []string, since time.Time) bool {
 	if rels, err := db.OutgoingRelations(asset, since, "ptr_record"); err == nil && len(rels) > 0 {
 		for _, rel := range rels {
 			if to, err := db.FindById(rel.ToAsset.ID, since); err == nil {
-				if n, ok := to.Asset.(*domain.FQDN); ok && n != nil && domainNameInScope(n.Name, scope) {
+				if n, ok := to.Asset.(*domain.FQDN); ok && n != nil && requests.DomainNameInScope(n.Name, scope) {
 					return true
 				}
 			}
@@ -292,7 +393,7 @@ func followBackForScope(db *assetdb.AssetDB, asset *types.Asset, scope []string,
 	if rels, err := db.IncomingRelations(asset, since, ins...); err == nil && len(rels) > 0 {
 		for _, rel := range rels {
 			if from, err := db.FindById(rel.FromAsset.ID, since); err == nil {
-				if n, ok := from.Asset.(*domain.FQDN); ok && n != nil && domainNameInScope(n.Name, scope) {
+				if n, ok := from.Asset.(*domain.FQDN); ok && n != nil && requests.DomainNameInScope(n.Name, scope) {
 					return true
 				} else if followBackForScope(db, from, scope, since) {
 					return true
@@ -6,10 +6,13 @@ package viz
 
 import (
 	"context"
+	"net/netip"
 	"testing"
 	"time"
 
 	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/open-asset-model/network"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestViz(t *testing.T) {
@@ -67,3 +70,40 @@ func testNodes() []Node {
 		},
 	}
 }
+
+func TestEgoData(t *testing.T) {
+	g := graph.NewGraph("memory", "", "")
+
+	_, err := g.UpsertA(context.Background(), "www.egotest.domain", "198.51.100.20")
+	assert.Nil(t, err)
+	_, err = g.UpsertA(context.Background(), "api.egotest.domain", "198.51.100.20")
+	assert.Nil(t, err)
+	_, err = g.UpsertCNAME(context.Background(), "cdn.egotest.domain", "www.egotest.domain")
+	assert.Nil(t, err)
+
+	addr := &network.IPAddress{Address: netip.MustParseAddr("198.51.100.20"), Type: "IPv4"}
+	nodes, edges := EgoData(addr, 1, time.Time{}, g)
+	assert.ElementsMatch(t, []string{"198.51.100.20", "www.egotest.domain", "api.egotest.domain"}, nodeLabels(nodes))
+	assert.Len(t, edges, 2)
+	for _, e := range edges {
+		assert.Equal(t, "a_record", e.Label)
+		assert.Equal(t, "198.51.100.20", nodes[e.To].Label)
+	}
+
+	nodes, edges = EgoData(addr, 2, time.Time{}, g)
+	assert.Contains(t, nodeLabels(nodes), "cdn.egotest.domain")
+	assert.Len(t, edges, 3)
+
+	nodes, edges = EgoData(&network.IPAddress{Address: netip.MustParseAddr("198.51.100.21"), Type: "IPv4"}, 2, time.Time{}, g)
+	assert.Empty(t, nodes)
+	assert.Empty(t, edges)
+}
+
+func nodeLabels(nodes []Node) []string {
+	var labels []string
+
+	for _, n := range nodes {
+		labels = append(labels, n.Label)
+	}
+	return labels
+}